@@ -3,28 +3,59 @@
 // @description API для управления подписками пользователей
 // @host localhost:8080
 // @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT bearer token: "Bearer <token>"
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description Service API key: "ApiKey <key>"
 package main
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	_ "github.com/lib/pq"
 	_ "subscription-service/docs"
+	"subscription-service/internal/auth"
 	"subscription-service/internal/config"
 	httpDelivery "subscription-service/internal/delivery/http"
+	"subscription-service/internal/events"
+	"subscription-service/internal/health"
+	"subscription-service/internal/metrics"
+	"subscription-service/internal/notify"
+	"subscription-service/internal/ratelimit"
+	"subscription-service/internal/tracing"
 	"subscription-service/pkg/logger"
 
 	"subscription-service/pkg/storage"
 	"subscription-service/internal/storage/postgres"
+	"subscription-service/internal/usecase/apikey"
+	"subscription-service/internal/usecase/catalog"
+	"subscription-service/internal/usecase/feed"
+	"subscription-service/internal/usecase/notification"
+	"subscription-service/internal/usecase/reminder"
 	"subscription-service/internal/usecase/subscription"
+	"subscription-service/internal/usecase/webhook"
 )
 
 const defaultConfigPath string = "config/config.example.yaml"
 
+// brokerConnectTimeout bounds how long startup waits for the message broker.
+const brokerConnectTimeout = 10 * time.Second
+
 func main() {
 	path := os.Getenv("CONFIG_PATH")
 	if path == "" {
@@ -41,18 +72,353 @@ func main() {
 	slog.SetDefault(logger.Log)
 	slog.Info("config loaded:", "path", path)
 
+	shutdownTracing := setupTracing(cfg)
+
 	db := storage.NewPostgresDB(storage.Config(cfg.Database))
-	defer db.Close()
+	// Row-level security limits db to the tenant of each request; jobs that
+	// work across tenants use workerDB, whose role bypasses it.
+	workerDB := storage.NewWorkerDB(storage.Config(cfg.Database))
+	if workerDB == nil {
+		workerDB = db
+	}
+
+	catalogStorage := postgres.NewCatalogStorage(db, logger.Log)
+	catalogService := catalog.NewService(catalogStorage, logger.Log)
+	catalogHandler := httpDelivery.NewCatalogHandler(catalogService, logger.Log)
 
 	storage := postgres.NewSubscriptionStorage(db, logger.Log)
-	service := subscription.NewService(storage, logger.Log)
+	webhookStorage := postgres.NewWebhookStorage(db, logger.Log)
+	webhookService := webhook.NewService(webhookStorage, logger.Log)
+	webhookHandler := httpDelivery.NewWebhookHandler(webhookService, logger.Log)
+
+	outbox := postgres.NewOutboxStorage(db, logger.Log)
+	feedService := feed.NewService(outbox, cfg.Feed.PollInterval, logger.Log)
+	feedHandler := httpDelivery.NewFeedHandler(feedService, logger.Log)
+
+	service := subscription.NewService(storage, catalogService, logger.Log)
 	handler := httpDelivery.NewHandler(service, logger.Log)
-	router := httpDelivery.NewRouter(handler, logger.Log)
+	apiKeyService := apikey.NewService(postgres.NewAPIKeyStorage(db, workerDB, logger.Log), logger.Log)
+	apiKeyHandler := httpDelivery.NewAPIKeyHandler(apiKeyService, logger.Log)
+
+	authenticator := newAuthenticator(cfg, apiKeyService)
+	tenants := httpDelivery.NewTenantResolver(cfg.Tenancy.Header, cfg.Tenancy.DefaultTenant, cfg.Tenancy.Tenants)
+	limiter := newRateLimiter(cfg, db)
+
+	healthStorage := postgres.NewHealthStorage(db, logger.Log)
+	checker := health.NewChecker(cfg.Health.Timeout)
+	checker.Add("database", healthStorage.Ping)
+	checker.Add("migrations", healthStorage.CheckSchema)
+
+	// Background workers share a context that is cancelled on shutdown once
+	// the server has drained. Each one is also a readiness check.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	runWorker := func(name string, w worker) {
+		heartbeat := health.NewHeartbeat()
+		w.SetHeartbeat(heartbeat)
+		checker.Add("worker:"+name, heartbeat.Check)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			defer heartbeat.Stop()
+			w.Run(workerCtx)
+		}()
+	}
+
+	publisher := events.Multi{events.NewLogPublisher(logger.Log), webhookService}
+	broker := newBrokerPublisher(cfg)
+	if broker != nil {
+		publisher = append(publisher, broker)
+	}
+	relay := events.NewRelay(postgres.NewOutboxStorage(workerDB, logger.Log), publisher, cfg.Outbox.Interval, cfg.Outbox.BatchSize, cfg.Outbox.MaxAttempts, logger.Log)
+	runWorker("outbox_relay", relay)
+
+	workerStorage := postgres.NewSubscriptionStorage(workerDB, logger.Log)
+	notificationStorage := postgres.NewNotificationStorage(workerDB, logger.Log)
+	if cfg.Reminders.Enabled {
+		scheduler := reminder.NewScheduler(workerStorage, notificationStorage, cfg.Reminders.WindowDays, cfg.Reminders.Interval, logger.Log)
+		runWorker("reminder_scheduler", scheduler)
+	}
+
+	if cfg.Webhooks.Enabled {
+		wc := cfg.Webhooks
+		dispatcher := webhook.NewDispatcher(postgres.NewWebhookStorage(workerDB, logger.Log), webhook.Config{
+			Interval:    wc.Interval,
+			BatchSize:   wc.BatchSize,
+			MaxAttempts: wc.MaxAttempts,
+			Backoff:     wc.Backoff,
+			MaxBackoff:  wc.MaxBackoff,
+			Timeout:     wc.Timeout,
+		}, logger.Log)
+		runWorker("webhook_dispatcher", dispatcher)
+	}
+
+	if cfg.Notifications.Enabled {
+		n := cfg.Notifications
+		dispatcher := notification.NewDispatcher(notificationStorage, newNotifier(cfg), notification.Config{
+			Interval:    n.Interval,
+			BatchSize:   n.BatchSize,
+			MaxAttempts: n.MaxAttempts,
+			Backoff:     n.Backoff,
+			MaxBackoff:  n.MaxBackoff,
+			Timeout:     n.Timeout,
+		}, logger.Log)
+		runWorker("notification_dispatcher", dispatcher)
+	}
+
+	healthHandler := httpDelivery.NewHealthHandler(checker, logger.Log)
+	metricsHandler := newMetricsHandler(cfg, db, storage, workerStorage)
+	router := httpDelivery.NewRouter(handler, catalogHandler, webhookHandler, feedHandler, apiKeyHandler, healthHandler, metricsHandler, authenticator, tenants, limiter, logger.Log)
+	server := newServer(cfg, httpDelivery.MaxBodySize(maxBodyBytes(cfg))(router))
+	server.RegisterOnShutdown(feedHandler.Shutdown)
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server starting", "addr", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		slog.Error("server failed", "error", err)
+		exitCode = 1
+	case <-signals.Done():
+		slog.Info("shutdown started")
+	}
+	stopSignals()
+
+	// Shut down in dependency order: stop taking requests and drain the
+	// in-flight ones, then stop the workers, then flush the traces and close
+	// the broker and the database they use. Everything shares one deadline.
+	deadline, cancel := context.WithTimeout(context.Background(), shutdownTimeout(cfg))
+	defer cancel()
+
+	if err := server.Shutdown(deadline); err != nil {
+		slog.Error("server did not drain in time, closing remaining connections", "error", err)
+		server.Close()
+	}
+
+	stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		slog.Info("background workers stopped")
+	case <-deadline.Done():
+		slog.Warn("background workers did not stop in time")
+	}
+
+	if err := shutdownTracing(deadline); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+	if closer, ok := broker.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			slog.Error("failed to close broker publisher", "error", err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		slog.Error("failed to close database", "error", err)
+	}
+	if workerDB != db {
+		if err := workerDB.Close(); err != nil {
+			slog.Error("failed to close worker database", "error", err)
+		}
+	}
+
+	slog.Info("shutdown complete")
+	os.Exit(exitCode)
+}
+
+// worker is a background loop that reports its progress for readiness.
+type worker interface {
+	Run(ctx context.Context)
+	SetHeartbeat(h *health.Heartbeat)
+}
+
+// newServer applies the configured timeouts, falling back to defaults that
+// keep slow clients from holding connections open indefinitely.
+func newServer(cfg *config.Config, handler http.Handler) *http.Server {
+	s := cfg.Server
+	orDefault := func(d, def time.Duration) time.Duration {
+		if d <= 0 {
+			return def
+		}
+		return d
+	}
+	return &http.Server{
+		Addr:              fmt.Sprintf("%s:%s", s.Host, s.Port),
+		Handler:           handler,
+		ReadTimeout:       orDefault(s.ReadTimeout, 15*time.Second),
+		ReadHeaderTimeout: orDefault(s.ReadHeaderTimeout, 5*time.Second),
+		WriteTimeout:      orDefault(s.WriteTimeout, 30*time.Second),
+		IdleTimeout:       orDefault(s.IdleTimeout, 60*time.Second),
+		ErrorLog:          slog.NewLogLogger(logger.Log.Handler(), slog.LevelWarn),
+	}
+}
+
+// defaultMaxBodyBytes limits request bodies when server.max_body_bytes is
+// unset.
+const defaultMaxBodyBytes = 1 << 20
+
+func maxBodyBytes(cfg *config.Config) int64 {
+	if cfg.Server.MaxBodyBytes <= 0 {
+		return defaultMaxBodyBytes
+	}
+	return cfg.Server.MaxBodyBytes
+}
+
+func shutdownTimeout(cfg *config.Config) time.Duration {
+	if cfg.Server.ShutdownTimeout <= 0 {
+		return 20 * time.Second
+	}
+	return cfg.Server.ShutdownTimeout
+}
+
+// newAuthenticator loads the JWT keys when authentication is enabled. Keys
+// that cannot be loaded are fatal rather than leaving the API open. API keys
+// are accepted alongside JWTs.
+func newAuthenticator(cfg *config.Config, keys *apikey.Service) *httpDelivery.Authenticator {
+	a := cfg.Auth
+	if !a.Enabled {
+		slog.Warn("authentication disabled, the API is public")
+		return nil
+	}
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{
+		HS256Secret:  a.HS256Secret,
+		RS256KeyFile: a.RS256PublicKey,
+		JWKSFile:     a.JWKSFile,
+		Issuer:       a.Issuer,
+		Audience:     a.Audience,
+		RolesClaim:   a.RolesClaim,
+		TenantClaim:  a.TenantClaim,
+	})
+	if err != nil {
+		log.Fatalf("failed to configure authentication: %v", err)
+	}
+	return httpDelivery.NewAuthenticator(verifier, keys, logger.Log)
+}
+
+// setupTracing installs the configured span exporter and returns the
+// function that flushes it on shutdown. With tracing disabled spans are
+// discarded.
+func setupTracing(cfg *config.Config) func(context.Context) error {
+	t := cfg.Tracing
+	if !t.Enabled {
+		return func(context.Context) error { return nil }
+	}
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    t.Exporter,
+		Endpoint:    t.Endpoint,
+		Insecure:    t.Insecure,
+		ServiceName: t.ServiceName,
+		SampleRatio: t.SampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("failed to configure tracing: %v", err)
+	}
+	slog.Info("tracing enabled", "exporter", t.Exporter, "endpoint", t.Endpoint)
+	return shutdown
+}
+
+// newMetricsHandler registers the metrics of the HTTP server, the database
+// pool, the subscription storage and the business figures, or returns nil
+// when metrics are disabled. The business figures cover every tenant and
+// are read through the worker storage.
+func newMetricsHandler(cfg *config.Config, db *sql.DB, subscriptions, workerSubscriptions *postgres.SubscriptionStorage) *httpDelivery.MetricsHandler {
+	if !cfg.Metrics.Enabled {
+		return nil
+	}
+	// The metrics show every tenant's figures, so they may only be public
+	// on a deployment that is open anyway.
+	if cfg.Metrics.Token == "" && (cfg.Auth.Enabled || len(cfg.Tenancy.Tenants) > 0) {
+		log.Fatal("metrics.token is required when auth is enabled or tenancy.tenants is set")
+	}
+	m := metrics.New()
+	m.RegisterDB(db, cfg.Database.Name)
+	m.Register(metrics.NewBusinessCollector(workerSubscriptions, logger.Log))
+	subscriptions.SetQueryObserver(m)
+	workerSubscriptions.SetQueryObserver(m)
+	return httpDelivery.NewMetricsHandler(m, m.Handler(), cfg.Metrics.Token)
+}
+
+// newRateLimiter builds the per-client rate limits from the config, or
+// returns nil when rate limiting is disabled.
+func newRateLimiter(cfg *config.Config, db *sql.DB) *httpDelivery.RateLimiter {
+	rl := cfg.RateLimit
+	if !rl.Enabled {
+		return nil
+	}
+
+	var store ratelimit.Store
+	switch rl.Store {
+	case "", "memory":
+		store = ratelimit.NewMemoryStore()
+	case "postgres":
+		store = postgres.NewRateLimitStorage(db, logger.Log)
+	default:
+		log.Fatalf("unknown rate limit store %q", rl.Store)
+	}
+
+	limit := func(rule config.RateLimitRule) ratelimit.Limit {
+		if rule.Requests <= 0 || rule.Per <= 0 {
+			return ratelimit.Limit{}
+		}
+		return ratelimit.PerPeriod(rule.Requests, rule.Per, rule.Burst)
+	}
+	groups := make(map[string]ratelimit.Limit, len(rl.Groups))
+	for name, rule := range rl.Groups {
+		groups[name] = limit(rule)
+	}
+	return httpDelivery.NewRateLimiter(store, limit(rl.Default), groups, logger.Log)
+}
+
+// newNotifier picks the notification channel from the config, falling back
+// to the log.
+func newNotifier(cfg *config.Config) notification.Notifier {
+	n := cfg.Notifications
+	switch n.Channel {
+	case "smtp":
+		return notify.NewSMTPNotifier(n.SMTP.Host, n.SMTP.Port, n.SMTP.Username, n.SMTP.Password, n.SMTP.From, n.SMTP.Recipient)
+	case "webhook":
+		return notify.NewWebhookNotifier(n.Webhook.URL, n.Timeout)
+	default:
+		return notify.NewLogNotifier(logger.Log)
+	}
+}
 
-	addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
-	slog.Info("server starting", "addr", addr)
-	if err := http.ListenAndServe(addr, router); err != nil {
-    slog.Error("server failed", "error", err)
-    os.Exit(1)
+// newBrokerPublisher connects to the message broker selected in the config,
+// if any. A broker that is configured but unreachable within
+// brokerConnectTimeout is fatal.
+func newBrokerPublisher(cfg *config.Config) events.Publisher {
+	b := cfg.Broker
+	switch b.Kind {
+	case "":
+		return nil
+	case "nats":
+		ctx, cancel := context.WithTimeout(context.Background(), brokerConnectTimeout)
+		defer cancel()
+		p, err := events.NewNATSPublisher(ctx, b.NATS.URL, b.NATS.Stream, b.NATS.SubjectPrefix, logger.Log)
+		if err != nil {
+			log.Fatalf("failed to connect to nats: %v", err)
+		}
+		return p
+	case "kafka":
+		ctx, cancel := context.WithTimeout(context.Background(), brokerConnectTimeout)
+		defer cancel()
+		p, err := events.NewKafkaPublisher(ctx, b.Kafka.Brokers, b.Kafka.Topic, logger.Log)
+		if err != nil {
+			log.Fatalf("failed to connect to kafka: %v", err)
+		}
+		return p
+	default:
+		log.Fatalf("unknown broker kind %q", b.Kind)
+		return nil
 	}
 }
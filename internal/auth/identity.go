@@ -0,0 +1,85 @@
+// Package auth authenticates callers and carries their identity through
+// the request context.
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Identity is an authenticated caller. Subject is the token subject, which
+// for end users is their user ID; what they may do follows from their
+// Roles. API key clients act for every user but only within their Scopes.
+// Tenant is the tenant the credential was issued for.
+type Identity struct {
+	Subject string
+	Roles   []Role
+	APIKey  bool
+	Scopes  []domain.Scope
+	Tenant  string
+}
+
+// Allows reports whether the caller may perform operations in scope. Scopes
+// only limit API key clients; end users are limited by ownership instead.
+func (i *Identity) Allows(scope domain.Scope) bool {
+	return !i.APIKey || slices.Contains(i.Scopes, scope)
+}
+
+// UserID parses the subject as a user ID.
+func (i *Identity) UserID() (uuid.UUID, bool) {
+	id, err := uuid.Parse(i.Subject)
+	return id, err == nil
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the caller identity, or nil when the request was not
+// authenticated, e.g. because authentication is disabled.
+func FromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// Authorize checks the caller may perform action and returns the user it
+// is limited to. Requests without an identity, which only happens with
+// authentication disabled, are unrestricted. Callers with own reach whose
+// subject is not a user ID own nothing and are refused.
+func Authorize(ctx context.Context, action Action) (userID uuid.UUID, restricted bool, err error) {
+	identity := FromContext(ctx)
+	if identity == nil {
+		return uuid.Nil, false, nil
+	}
+	switch identity.Reach(action) {
+	case ReachAll:
+		return uuid.Nil, false, nil
+	case ReachOwn:
+		userID, ok := identity.UserID()
+		if !ok {
+			return uuid.Nil, true, domain.ErrForbidden
+		}
+		return userID, true, nil
+	default:
+		return uuid.Nil, true, domain.ErrForbidden
+	}
+}
+
+// Require checks the caller may perform action for every user, as needed
+// for operations not tied to a single user's subscriptions.
+func Require(ctx context.Context, action Action) error {
+	_, restricted, err := Authorize(ctx, action)
+	if err != nil {
+		return err
+	}
+	if restricted {
+		return domain.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew is how far exp and nbf may be off before a token is rejected.
+const clockSkew = 30 * time.Second
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// JWTConfig selects the accepted keys. Any combination may be set; HS256
+// tokens are checked against HS256Secret and RS256 tokens against the PEM
+// key and the keys of the JWKS file.
+type JWTConfig struct {
+	HS256Secret  string
+	RS256KeyFile string
+	JWKSFile     string
+	Issuer       string
+	Audience     string
+	RolesClaim   string
+	TenantClaim  string
+}
+
+// JWTVerifier validates HS256 and RS256 signed JWTs.
+type JWTVerifier struct {
+	secret      []byte
+	rsaKeys     map[string]*rsa.PublicKey
+	issuer      string
+	audience    string
+	rolesClaim  string
+	tenantClaim string
+	now         func() time.Time
+}
+
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		rsaKeys:     make(map[string]*rsa.PublicKey),
+		issuer:      cfg.Issuer,
+		audience:    cfg.Audience,
+		rolesClaim:  cfg.RolesClaim,
+		tenantClaim: cfg.TenantClaim,
+		now:         time.Now,
+	}
+	if v.rolesClaim == "" {
+		v.rolesClaim = "roles"
+	}
+	if v.tenantClaim == "" {
+		v.tenantClaim = "tenant_id"
+	}
+	if cfg.HS256Secret != "" {
+		v.secret = []byte(cfg.HS256Secret)
+	}
+
+	if cfg.RS256KeyFile != "" {
+		key, err := loadRSAPublicKey(cfg.RS256KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load rs256 key: %w", err)
+		}
+		v.rsaKeys[""] = key
+	}
+	if cfg.JWKSFile != "" {
+		if err := v.loadJWKS(cfg.JWKSFile); err != nil {
+			return nil, fmt.Errorf("load jwks: %w", err)
+		}
+	}
+
+	if v.secret == nil && len(v.rsaKeys) == 0 {
+		return nil, errors.New("no jwt keys configured")
+	}
+	return v, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks the signature and registered claims of token and returns
+// the caller identity with the roles from the roles claim. Tokens without
+// roles get the user role; unknown roles are ignored. Tokens must name
+// their tenant, as the tenant header is not trusted once callers
+// authenticate.
+func (v *JWTVerifier) Verify(token string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := v.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return v.identity(claims)
+}
+
+func (v *JWTVerifier) verifySignature(header jwtHeader, signed string, signature []byte) error {
+	switch header.Alg {
+	case "HS256":
+		if v.secret == nil {
+			return ErrInvalidToken
+		}
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrInvalidToken
+		}
+		return nil
+	case "RS256":
+		key := v.rsaKey(header.Kid)
+		if key == nil {
+			return ErrInvalidToken
+		}
+		digest := sha256.Sum256([]byte(signed))
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+			return ErrInvalidToken
+		}
+		return nil
+	default:
+		// Rejects "none" and algorithms we hold no keys for.
+		return ErrInvalidToken
+	}
+}
+
+// rsaKey picks the key named by kid, falling back to the only configured
+// key when the token names none.
+func (v *JWTVerifier) rsaKey(kid string) *rsa.PublicKey {
+	if key, ok := v.rsaKeys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(v.rsaKeys) == 1 {
+		for _, key := range v.rsaKeys {
+			return key
+		}
+	}
+	return nil
+}
+
+func (v *JWTVerifier) identity(claims map[string]any) (*Identity, error) {
+	now := v.now()
+
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	if now.After(exp.Add(clockSkew)) {
+		return nil, ErrExpiredToken
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(clockSkew).Before(nbf) {
+		return nil, ErrInvalidToken
+	}
+	if v.issuer != "" && claims["iss"] != v.issuer {
+		return nil, ErrInvalidToken
+	}
+	if v.audience != "" && !slices.Contains(stringsClaim(claims, "aud"), v.audience) {
+		return nil, ErrInvalidToken
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, ErrInvalidToken
+	}
+	tenant, _ := claims[v.tenantClaim].(string)
+	if tenant == "" {
+		return nil, ErrInvalidToken
+	}
+	identity := &Identity{Subject: subject, Tenant: tenant}
+	names := stringsClaim(claims, v.rolesClaim)
+	if len(names) == 0 {
+		identity.Roles = []Role{RoleUser}
+	}
+	for _, name := range names {
+		if role := Role(name); role.Valid() {
+			identity.Roles = append(identity.Roles, role)
+		}
+	}
+	return identity, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func numericClaim(claims map[string]any, name string) (time.Time, bool) {
+	n, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(n), 0), true
+}
+
+// stringsClaim reads a claim that may be a single string or an array.
+func stringsClaim(claims map[string]any, name string) []string {
+	switch c := claims[name].(type) {
+	case string:
+		return []string{c}
+	case []any:
+		out := make([]string, 0, len(c))
+		for _, item := range c {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("not an RSA public key")
+		}
+		return rsaKey, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.New("unsupported PEM key format")
+	}
+	rsaKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("certificate does not hold an RSA key")
+	}
+	return rsaKey, nil
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// loadJWKS adds the RSA signing keys of a local JWKS file. Keys of other
+// types or meant for encryption are skipped.
+func (v *JWTVerifier) loadJWKS(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return fmt.Errorf("key %q: invalid modulus", k.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return fmt.Errorf("key %q: invalid exponent", k.Kid)
+		}
+		v.rsaKeys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "hs256-test-secret"
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret string, header, claims map[string]any) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, header, claims map[string]any) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func writePublicKey(t *testing.T, key *rsa.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := writePublicKey(t, rsaKey)
+	pemBytes, err := os.ReadFile(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	claims := func(extra map[string]any) map[string]any {
+		c := map[string]any{
+			"sub":       "alice",
+			"iss":       "https://issuer.example.com",
+			"aud":       []string{"subscriptions"},
+			"tenant_id": "acme",
+			"exp":       now.Add(time.Hour).Unix(),
+		}
+		for k, v := range extra {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = v
+		}
+		return c
+	}
+	hs := map[string]any{"alg": "HS256", "typ": "JWT"}
+	rs := map[string]any{"alg": "RS256", "typ": "JWT"}
+
+	tests := []struct {
+		name      string
+		token     string
+		wantErr   error
+		wantRoles []Role
+	}{
+		{
+			name:      "valid HS256",
+			token:     signHS256(t, testSecret, hs, claims(nil)),
+			wantRoles: []Role{RoleUser},
+		},
+		{
+			name:      "valid RS256",
+			token:     signRS256(t, rsaKey, rs, claims(map[string]any{"roles": []string{"admin", "unknown"}})),
+			wantRoles: []Role{RoleAdmin},
+		},
+		{
+			name:    "expired",
+			token:   signHS256(t, testSecret, hs, claims(map[string]any{"exp": now.Add(-time.Minute).Unix()})),
+			wantErr: ErrExpiredToken,
+		},
+		{
+			name:      "expired within clock skew",
+			token:     signHS256(t, testSecret, hs, claims(map[string]any{"exp": now.Add(-10 * time.Second).Unix()})),
+			wantRoles: []Role{RoleUser},
+		},
+		{
+			name:    "not yet valid",
+			token:   signHS256(t, testSecret, hs, claims(map[string]any{"nbf": now.Add(time.Hour).Unix()})),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "no expiry",
+			token:   signHS256(t, testSecret, hs, claims(map[string]any{"exp": nil})),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "alg none without signature",
+			token:   encodeSegment(t, map[string]any{"alg": "none"}) + "." + encodeSegment(t, claims(nil)) + ".",
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "alg none with a valid HS256 signature",
+			token:   signHS256(t, testSecret, map[string]any{"alg": "none"}, claims(nil)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "HS256 with the wrong secret",
+			token:   signHS256(t, "another-secret", hs, claims(nil)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "RS256 with the wrong key",
+			token:   signRS256(t, otherKey, rs, claims(nil)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "RS256 with an unknown kid",
+			token:   signRS256(t, rsaKey, map[string]any{"alg": "RS256", "kid": "rotated"}, claims(nil)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "HS256 keyed with the RSA public key",
+			token:   signHS256(t, string(pemBytes), hs, claims(nil)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "tampered claims",
+			token:   tamper(t, signHS256(t, testSecret, hs, claims(nil)), claims(map[string]any{"sub": "mallory"})),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "wrong issuer",
+			token:   signHS256(t, testSecret, hs, claims(map[string]any{"iss": "https://evil.example.com"})),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "wrong audience",
+			token:   signHS256(t, testSecret, hs, claims(map[string]any{"aud": "billing"})),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "no tenant",
+			token:   signHS256(t, testSecret, hs, claims(map[string]any{"tenant_id": nil})),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "empty tenant",
+			token:   signHS256(t, testSecret, hs, claims(map[string]any{"tenant_id": ""})),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "no subject",
+			token:   signHS256(t, testSecret, hs, claims(map[string]any{"sub": nil})),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "malformed",
+			token:   "not.a-jwt",
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	v, err := NewJWTVerifier(JWTConfig{
+		HS256Secret:  testSecret,
+		RS256KeyFile: keyFile,
+		Issuer:       "https://issuer.example.com",
+		Audience:     "subscriptions",
+	})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier: %v", err)
+	}
+	v.now = func() time.Time { return now }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := v.Verify(tt.token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if identity.Subject != "alice" || identity.Tenant != "acme" {
+				t.Errorf("identity = %+v, want alice of acme", identity)
+			}
+			if !slices.Equal(identity.Roles, tt.wantRoles) {
+				t.Errorf("roles = %v, want %v", identity.Roles, tt.wantRoles)
+			}
+		})
+	}
+}
+
+// tamper swaps the claims of a signed token and keeps its signature.
+func tamper(t *testing.T, token string, claims map[string]any) string {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	return parts[0] + "." + encodeSegment(t, claims) + "." + parts[2]
+}
+
+func TestNewJWTVerifierWithoutKeys(t *testing.T) {
+	if _, err := NewJWTVerifier(JWTConfig{}); err == nil {
+		t.Fatal("NewJWTVerifier accepted a config without keys")
+	}
+}
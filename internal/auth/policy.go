@@ -0,0 +1,99 @@
+package auth
+
+import "subscription-service/internal/domain"
+
+// Role is a set of permissions granted to end users through the roles
+// claim of their token.
+type Role string
+
+const (
+	RoleViewer  Role = "viewer"
+	RoleUser    Role = "user"
+	RoleSupport Role = "support"
+	RoleAdmin   Role = "admin"
+)
+
+func (r Role) Valid() bool {
+	_, ok := policy[r]
+	return ok
+}
+
+// Action is an operation checked against the policy.
+type Action string
+
+const (
+	ActionSubscriptionsRead   Action = "subscriptions:read"
+	ActionSubscriptionsWrite  Action = "subscriptions:write"
+	ActionSubscriptionsDelete Action = "subscriptions:delete"
+	ActionReportsRead         Action = "reports:read"
+	ActionCatalogManage       Action = "catalog:manage"
+	ActionWebhooksManage      Action = "webhooks:manage"
+	ActionAPIKeysManage       Action = "api_keys:manage"
+)
+
+// Reach is whose data a permission covers.
+type Reach int
+
+const (
+	ReachNone Reach = iota
+	ReachOwn
+	ReachAll
+)
+
+// policy maps each role to the actions it may perform. Own reach limits an
+// action to subscriptions the caller owns or shares; writes further require
+// ownership. Callers with several roles get the widest reach any of them
+// grants.
+var policy = map[Role]map[Action]Reach{
+	RoleViewer: {
+		ActionSubscriptionsRead: ReachOwn,
+		ActionReportsRead:       ReachOwn,
+	},
+	RoleUser: {
+		ActionSubscriptionsRead:   ReachOwn,
+		ActionSubscriptionsWrite:  ReachOwn,
+		ActionSubscriptionsDelete: ReachOwn,
+		ActionReportsRead:         ReachOwn,
+	},
+	RoleSupport: {
+		ActionSubscriptionsRead: ReachAll,
+		ActionReportsRead:       ReachAll,
+	},
+	RoleAdmin: {
+		ActionSubscriptionsRead:   ReachAll,
+		ActionSubscriptionsWrite:  ReachAll,
+		ActionSubscriptionsDelete: ReachAll,
+		ActionReportsRead:         ReachAll,
+		ActionCatalogManage:       ReachAll,
+		ActionWebhooksManage:      ReachAll,
+		ActionAPIKeysManage:       ReachAll,
+	},
+}
+
+// scopeActions maps API key scopes to the actions they allow. Keys act for
+// every user, so their reach is always all.
+var scopeActions = map[domain.Scope][]Action{
+	domain.ScopeSubscriptionsRead:  {ActionSubscriptionsRead},
+	domain.ScopeSubscriptionsWrite: {ActionSubscriptionsWrite, ActionSubscriptionsDelete},
+	domain.ScopeReportsRead:        {ActionReportsRead},
+}
+
+// Reach returns how far the caller may perform action.
+func (i *Identity) Reach(action Action) Reach {
+	if i.APIKey {
+		for _, scope := range i.Scopes {
+			for _, a := range scopeActions[scope] {
+				if a == action {
+					return ReachAll
+				}
+			}
+		}
+		return ReachNone
+	}
+
+	reach := ReachNone
+	for _, role := range i.Roles {
+		reach = max(reach, policy[role][action])
+	}
+	return reach
+}
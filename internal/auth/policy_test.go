@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestAuthorize(t *testing.T) {
+	userID := uuid.MustParse("00000000-0000-0000-0000-0000000000aa")
+	user := func(roles ...Role) *Identity {
+		return &Identity{Subject: userID.String(), Roles: roles}
+	}
+	apiKey := func(scopes ...domain.Scope) *Identity {
+		return &Identity{Subject: "api-key:1", APIKey: true, Scopes: scopes}
+	}
+
+	tests := []struct {
+		name           string
+		identity       *Identity
+		action         Action
+		wantRestricted bool
+		wantErr        bool
+	}{
+		{name: "no identity is unrestricted", identity: nil, action: ActionCatalogManage},
+
+		{name: "viewer reads own", identity: user(RoleViewer), action: ActionSubscriptionsRead, wantRestricted: true},
+		{name: "viewer cannot write", identity: user(RoleViewer), action: ActionSubscriptionsWrite, wantErr: true},
+		{name: "viewer cannot manage the catalog", identity: user(RoleViewer), action: ActionCatalogManage, wantErr: true},
+
+		{name: "user writes own", identity: user(RoleUser), action: ActionSubscriptionsWrite, wantRestricted: true},
+		{name: "user deletes own", identity: user(RoleUser), action: ActionSubscriptionsDelete, wantRestricted: true},
+		{name: "user cannot manage the catalog", identity: user(RoleUser), action: ActionCatalogManage, wantErr: true},
+		{name: "user cannot manage webhooks", identity: user(RoleUser), action: ActionWebhooksManage, wantErr: true},
+		{
+			name:     "user with a non-uuid subject owns nothing",
+			identity: &Identity{Subject: "alice", Roles: []Role{RoleUser}}, action: ActionSubscriptionsRead,
+			wantErr: true,
+		},
+
+		{name: "support reads all", identity: user(RoleSupport), action: ActionSubscriptionsRead},
+		{name: "support reports on all", identity: user(RoleSupport), action: ActionReportsRead},
+		{name: "support cannot write", identity: user(RoleSupport), action: ActionSubscriptionsWrite, wantErr: true},
+		{name: "support cannot manage the catalog", identity: user(RoleSupport), action: ActionCatalogManage, wantErr: true},
+
+		{name: "admin writes all", identity: user(RoleAdmin), action: ActionSubscriptionsWrite},
+		{name: "admin manages the catalog", identity: user(RoleAdmin), action: ActionCatalogManage},
+		{name: "admin manages api keys", identity: user(RoleAdmin), action: ActionAPIKeysManage},
+
+		{name: "widest role wins", identity: user(RoleViewer, RoleSupport), action: ActionSubscriptionsRead},
+		{name: "own write with read all", identity: user(RoleUser, RoleSupport), action: ActionSubscriptionsWrite, wantRestricted: true},
+		{name: "no roles", identity: user(), action: ActionSubscriptionsRead, wantErr: true},
+
+		{name: "key reads with its scope", identity: apiKey(domain.ScopeSubscriptionsRead), action: ActionSubscriptionsRead},
+		{name: "key deletes with the write scope", identity: apiKey(domain.ScopeSubscriptionsWrite), action: ActionSubscriptionsDelete},
+		{name: "key without the scope", identity: apiKey(domain.ScopeSubscriptionsRead), action: ActionReportsRead, wantErr: true},
+		{name: "key cannot manage the catalog", identity: apiKey(domain.ScopeSubscriptionsWrite), action: ActionCatalogManage, wantErr: true},
+		{name: "key cannot manage api keys", identity: apiKey(domain.ScopeSubscriptionsWrite), action: ActionAPIKeysManage, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.identity != nil {
+				ctx = WithIdentity(ctx, tt.identity)
+			}
+
+			got, restricted, err := Authorize(ctx, tt.action)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, domain.ErrForbidden) {
+					t.Fatalf("Authorize() error = %v, want ErrForbidden", err)
+				}
+				if Require(ctx, tt.action) == nil {
+					t.Error("Require() allowed a denied action")
+				}
+				return
+			}
+			if restricted != tt.wantRestricted {
+				t.Errorf("Authorize() restricted = %v, want %v", restricted, tt.wantRestricted)
+			}
+			if restricted && got != userID {
+				t.Errorf("Authorize() user = %s, want %s", got, userID)
+			}
+
+			// Require only passes callers that may act for every user.
+			if err := Require(ctx, tt.action); (err != nil) != tt.wantRestricted {
+				t.Errorf("Require() error = %v, want error %v", err, tt.wantRestricted)
+			}
+		})
+	}
+}
@@ -2,28 +2,179 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
+	// Server timeouts bound how long a client may take over each part of a
+	// request; zero values get safe defaults. Event streams are exempt from
+	// WriteTimeout. On SIGTERM in-flight requests get ShutdownTimeout to
+	// finish. Request bodies are limited to MaxBodyBytes, 1 MiB when unset.
 	Server struct {
-		Host string `yaml:"host"`
-		Port string `yaml:"port"`
+		Host              string        `yaml:"host"`
+		Port              string        `yaml:"port"`
+		ReadTimeout       time.Duration `yaml:"read_timeout"`
+		ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+		WriteTimeout      time.Duration `yaml:"write_timeout"`
+		IdleTimeout       time.Duration `yaml:"idle_timeout"`
+		ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+		MaxBodyBytes      int64         `yaml:"max_body_bytes"`
 	} `yaml:"server"`
 
+	// Database is reached as User for requests. Background jobs and API key
+	// lookups work across tenants and connect as WorkerUser, a role with
+	// BYPASSRLS; without it they share User.
 	Database struct {
-		Host     string `yaml:"host"`
-		Port     string `yaml:"port"`
-		User     string `yaml:"user"`
-		Password string `yaml:"password"`
-		Name     string `yaml:"dbname"`
-		SSLMode  string `yaml:"sslmode"`
+		Host           string `yaml:"host"`
+		Port           string `yaml:"port"`
+		User           string `yaml:"user"`
+		Password       string `yaml:"password"`
+		Name           string `yaml:"dbname"`
+		SSLMode        string `yaml:"sslmode"`
+		WorkerUser     string `yaml:"worker_user"`
+		WorkerPassword string `yaml:"worker_password"`
 	} `yaml:"postgres"`
 
+	// Auth requires a JWT bearer token on the API. Tokens are accepted when
+	// signed with the HS256 secret or with an RSA key from the PEM file or
+	// the local JWKS file. The roles claim lists the caller's roles (viewer,
+	// user, support, admin).
+	Auth struct {
+		Enabled        bool   `yaml:"enabled"`
+		HS256Secret    string `yaml:"hs256_secret"`
+		RS256PublicKey string `yaml:"rs256_public_key"`
+		JWKSFile       string `yaml:"jwks_file"`
+		Issuer         string `yaml:"issuer"`
+		Audience       string `yaml:"audience"`
+		RolesClaim     string `yaml:"roles_claim"`
+		TenantClaim    string `yaml:"tenant_claim"`
+	} `yaml:"auth"`
+
+	// Tenancy selects the tenant of each request: the tenant claim of the
+	// token or the tenant of the API key when auth is enabled, else the
+	// header, else DefaultTenant. A non-empty Tenants list rejects all other
+	// tenants.
+	Tenancy struct {
+		Header        string   `yaml:"header"`
+		DefaultTenant string   `yaml:"default_tenant"`
+		Tenants       []string `yaml:"tenants"`
+	} `yaml:"tenancy"`
+
+	// RateLimit limits each client, identified by tenant and API key or
+	// token subject, or by peer IP address, per route group (read, write,
+	// reports, admin). Groups not listed use Default. Store is "memory" for
+	// a single instance or "postgres" to share the limits between replicas.
+	RateLimit struct {
+		Enabled bool                     `yaml:"enabled"`
+		Store   string                   `yaml:"store"`
+		Default RateLimitRule            `yaml:"default"`
+		Groups  map[string]RateLimitRule `yaml:"groups"`
+	} `yaml:"rate_limit"`
+
+	// Metrics serves Prometheus metrics at /metrics. They include business
+	// figures of every tenant, so a Token can be required as a bearer token;
+	// it must be set when auth is enabled or Tenants is set.
+	Metrics struct {
+		Enabled bool   `yaml:"enabled"`
+		Token   string `yaml:"token"`
+	} `yaml:"metrics"`
+
+	// Tracing exports OpenTelemetry spans of requests, service calls and SQL
+	// queries. Exporter is "otlp" (OTLP over HTTP to Endpoint) or "stdout".
+	Tracing struct {
+		Enabled     bool    `yaml:"enabled"`
+		Exporter    string  `yaml:"exporter"`
+		Endpoint    string  `yaml:"endpoint"`
+		Insecure    bool    `yaml:"insecure"`
+		ServiceName string  `yaml:"service_name"`
+		SampleRatio float64 `yaml:"sample_ratio"`
+	} `yaml:"tracing"`
+
+	// Health limits how long each readiness check may take.
+	Health struct {
+		Timeout time.Duration `yaml:"timeout"`
+	} `yaml:"health"`
+
+	Outbox struct {
+		Interval    time.Duration `yaml:"interval"`
+		BatchSize   int           `yaml:"batch_size"`
+		MaxAttempts int           `yaml:"max_attempts"`
+	} `yaml:"outbox"`
+
+	Feed struct {
+		PollInterval time.Duration `yaml:"poll_interval"`
+	} `yaml:"feed"`
+
+	// Broker selects an optional message broker for subscription events:
+	// "nats" (JetStream) or "kafka". Empty disables broker publishing.
+	Broker struct {
+		Kind string `yaml:"kind"`
+
+		NATS struct {
+			URL           string `yaml:"url"`
+			Stream        string `yaml:"stream"`
+			SubjectPrefix string `yaml:"subject_prefix"`
+		} `yaml:"nats"`
+
+		Kafka struct {
+			Brokers []string `yaml:"brokers"`
+			Topic   string   `yaml:"topic"`
+		} `yaml:"kafka"`
+	} `yaml:"broker"`
+
+	Reminders struct {
+		Enabled    bool          `yaml:"enabled"`
+		WindowDays int           `yaml:"window_days"`
+		Interval   time.Duration `yaml:"interval"`
+	} `yaml:"reminders"`
+
+	Notifications struct {
+		Enabled     bool          `yaml:"enabled"`
+		Channel     string        `yaml:"channel"`
+		Interval    time.Duration `yaml:"interval"`
+		BatchSize   int           `yaml:"batch_size"`
+		MaxAttempts int           `yaml:"max_attempts"`
+		Backoff     time.Duration `yaml:"backoff"`
+		MaxBackoff  time.Duration `yaml:"max_backoff"`
+		Timeout     time.Duration `yaml:"timeout"`
+
+		SMTP struct {
+			Host      string `yaml:"host"`
+			Port      string `yaml:"port"`
+			Username  string `yaml:"username"`
+			Password  string `yaml:"password"`
+			From      string `yaml:"from"`
+			Recipient string `yaml:"recipient"`
+		} `yaml:"smtp"`
+
+		Webhook struct {
+			URL string `yaml:"url"`
+		} `yaml:"webhook"`
+	} `yaml:"notifications"`
+
+	Webhooks struct {
+		Enabled     bool          `yaml:"enabled"`
+		Interval    time.Duration `yaml:"interval"`
+		BatchSize   int           `yaml:"batch_size"`
+		MaxAttempts int           `yaml:"max_attempts"`
+		Backoff     time.Duration `yaml:"backoff"`
+		MaxBackoff  time.Duration `yaml:"max_backoff"`
+		Timeout     time.Duration `yaml:"timeout"`
+	} `yaml:"webhooks"`
+
 	LogLevel string `yaml:"log_level"`
 }
 
+// RateLimitRule allows Requests per Per on average, with bursts of up to
+// Burst requests (Requests when zero).
+type RateLimitRule struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+	Burst    int           `yaml:"burst"`
+}
+
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
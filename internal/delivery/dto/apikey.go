@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"subscription-service/internal/domain"
+)
+
+// APIKeyRequestDTO issues an API key. Without expires_at the key is valid
+// until revoked.
+type APIKeyRequestDTO struct {
+	Name      string   `json:"name" example:"nightly-billing-export"`
+	Scopes    []string `json:"scopes" example:"subscriptions:read,reports:read"`
+	ExpiresAt *string  `json:"expires_at,omitempty" example:"2025-12-31T23:59:59Z"`
+}
+
+// APIKeyResponseDTO describes a key. The key itself is only included in the
+// response to its creation.
+type APIKeyResponseDTO struct {
+	ID        string   `json:"id" example:"3f2b8c1d-4e5a-4b6c-9d7e-8f9a0b1c2d3e"`
+	Name      string   `json:"name" example:"nightly-billing-export"`
+	Key       string   `json:"key,omitempty" example:"sk_Zm9vYmFyYmF6cXV4cXV1eGNvcmdlZ3JhdWx0"`
+	Prefix    string   `json:"prefix" example:"sk_Zm9vYmFy"`
+	Scopes    []string `json:"scopes" example:"subscriptions:read,reports:read"`
+	ExpiresAt *string  `json:"expires_at,omitempty" example:"2025-12-31T23:59:59Z"`
+	CreatedAt string   `json:"created_at" example:"2024-11-05T10:15:00Z"`
+	RevokedAt *string  `json:"revoked_at,omitempty" example:"2024-12-01T08:00:00Z"`
+}
+
+func (dto *APIKeyRequestDTO) Validate() error {
+	if strings.TrimSpace(dto.Name) == "" {
+		return errors.New("name is required")
+	}
+	if len(dto.Scopes) == 0 {
+		return errors.New("scopes must not be empty")
+	}
+	for _, s := range dto.Scopes {
+		if !domain.Scope(s).Valid() {
+			return errors.New("scopes must contain only subscriptions:read, subscriptions:write or reports:read")
+		}
+	}
+	if dto.ExpiresAt != nil {
+		t, err := time.Parse(time.RFC3339, *dto.ExpiresAt)
+		if err != nil {
+			return errors.New("expires_at must be an RFC 3339 timestamp")
+		}
+		if !t.After(time.Now()) {
+			return errors.New("expires_at must be in the future")
+		}
+	}
+	return nil
+}
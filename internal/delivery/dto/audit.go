@@ -0,0 +1,21 @@
+package dto
+
+import "encoding/json"
+
+// AuditEntryDTO is one recorded change. before and after are the full
+// subscription snapshots; changes lists only the fields that differ.
+type AuditEntryDTO struct {
+	ID        string                    `json:"id" example:"c3d2e1f0-9a8b-4c7d-8e6f-5a4b3c2d1e0f"`
+	Action    string                    `json:"action" example:"subscription.updated"`
+	Actor     string                    `json:"actor,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	RequestID string                    `json:"request_id,omitempty" example:"5f7c1c4e-7a39-4a4f-a0a9-2d5ad8d1c0b7"`
+	Before    json.RawMessage           `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage           `json:"after,omitempty" swaggertype:"object"`
+	Changes   map[string]FieldChangeDTO `json:"changes"`
+	ChangedAt string                    `json:"changed_at" example:"2024-11-05T10:15:00Z"`
+}
+
+type FieldChangeDTO struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"errors"
+
+	"subscription-service/internal/domain"
+)
+
+// CancelRequestDTO cancels a subscription. effective_month is the last
+// billed month (MM-YYYY) or day (YYYY-MM-DD) and defaults to the end of the
+// current billing period.
+type CancelRequestDTO struct {
+	EffectiveMonth *string `json:"effective_month,omitempty" example:"12-2024"`
+	Reason         string  `json:"reason" example:"too_expensive" enums:"too_expensive,not_using,switching_service,missing_features,technical_issues,temporary,other"`
+	Comment        string  `json:"comment,omitempty" example:"found a cheaper plan"`
+}
+
+type CancellationDTO struct {
+	Reason      string `json:"reason" example:"too_expensive"`
+	Comment     string `json:"comment,omitempty" example:"found a cheaper plan"`
+	CancelledAt string `json:"cancelled_at" example:"2024-11-05T10:15:00Z"`
+}
+
+func (dto *CancelRequestDTO) Validate() error {
+	if !domain.CancelReason(dto.Reason).Valid() {
+		return errors.New("reason is invalid, expected one of too_expensive, not_using, switching_service, missing_features, technical_issues, temporary, other")
+	}
+	if dto.EffectiveMonth != nil {
+		if _, err := domain.ParseYearMonth(*dto.EffectiveMonth); err != nil {
+			return errors.New("effective_month has invalid format, expected MM-YYYY or YYYY-MM-DD")
+		}
+	}
+	if len(dto.Comment) > 1000 {
+		return errors.New("comment must be at most 1000 characters")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+// DiscountRequestDTO describes a discount valid from start_date either until
+// end_date or for the given number of months ("first 3 months at 50%").
+// Without both the discount never expires.
+type DiscountRequestDTO struct {
+	Kind        string  `json:"kind" example:"percent" enums:"percent,fixed"`
+	Value       int     `json:"value" example:"50"`
+	StartDate   string  `json:"start_date" example:"07-2024"`
+	EndDate     *string `json:"end_date,omitempty" example:"09-2024"`
+	Months      *int    `json:"months,omitempty" example:"3"`
+	Description string  `json:"description,omitempty" example:"first 3 months at 50%"`
+}
+
+type DiscountResponseDTO struct {
+	ID          string  `json:"id" example:"9b2f6d1e-5c4a-4e3b-8f7a-1d2c3b4a5e6f"`
+	Kind        string  `json:"kind" example:"percent"`
+	Value       int     `json:"value" example:"50"`
+	StartDate   string  `json:"start_date" example:"07-2024"`
+	EndDate     *string `json:"end_date,omitempty" example:"09-2024"`
+	Description string  `json:"description,omitempty" example:"first 3 months at 50%"`
+}
+
+func (dto *DiscountRequestDTO) Validate() error {
+	switch dto.Kind {
+	case "percent":
+		if dto.Value <= 0 || dto.Value > 100 {
+			return errors.New("value must be between 1 and 100 for percent discounts")
+		}
+	case "fixed":
+		if dto.Value <= 0 {
+			return errors.New("value must be greater than 0")
+		}
+	default:
+		return errors.New("kind must be percent or fixed")
+	}
+
+	startTime, err := time.Parse("01-2006", dto.StartDate)
+	if err != nil {
+		return errors.New("start_date has invalid format, expected MM-YYYY")
+	}
+	if dto.EndDate != nil && dto.Months != nil {
+		return errors.New("end_date and months are mutually exclusive")
+	}
+	if dto.EndDate != nil {
+		endTime, err := time.Parse("01-2006", *dto.EndDate)
+		if err != nil {
+			return errors.New("end_date has invalid format, expected MM-YYYY")
+		}
+		if endTime.Before(startTime) {
+			return errors.New("end_date cannot be before start_date")
+		}
+	}
+	if dto.Months != nil && *dto.Months <= 0 {
+		return errors.New("months must be greater than 0")
+	}
+	return nil
+}
@@ -2,51 +2,147 @@ package dto
 
 import (
 	"errors"
+	"fmt"
 	"strings"
-	"time"
+
+	"subscription-service/internal/domain"
 
 	"github.com/google/uuid"
 )
 
+// SubscriptionRequestDTO accepts start_date and end_date either as MM-YYYY or
+// as a full YYYY-MM-DD date. billing_day is the day of month the charge
+// happens on and defaults to the day of a full start_date, else the 1st.
 type SubscriptionRequestDTO struct {
-	ServiceName string  `json:"service_name" example:"Netflix"`
-	Price       int     `json:"price" example:"499"`
-	UserID      string  `json:"user_id" example:"e5c7c66b-4a3e-4728-84d9-b6c6b46ef1a6"`
-	StartDate   string  `json:"start_date" example:"07-2024"`
-	EndDate     *string `json:"end_date,omitempty" example:"12-2024"`
+	ServiceName  string      `json:"service_name" example:"Netflix"`
+	ServiceID    *string     `json:"service_id,omitempty" example:"3f1c2a8e-7d7b-4c61-9d3e-2f0a4b5c6d7e"`
+	Price        int         `json:"price" example:"499"`
+	UserID       string      `json:"user_id" example:"e5c7c66b-4a3e-4728-84d9-b6c6b46ef1a6"`
+	StartDate    string      `json:"start_date" example:"07-2024"`
+	EndDate      *string     `json:"end_date,omitempty" example:"12-2024"`
+	BillingDay   *int        `json:"billing_day,omitempty" example:"15"`
+	TrialEndDate *string     `json:"trial_end_date,omitempty" example:"08-2024"`
+	Categories   []string    `json:"categories,omitempty" example:"streaming"`
+	Tags         []string    `json:"tags,omitempty" example:"family,work"`
+	Members      []MemberDTO `json:"members,omitempty"`
 }
 
 type SubscriptionResponseDTO struct {
-	ID          string  `json:"id" example:"696c530f-b6c5-467f-ab70-45916e72daa7"`
-	ServiceName string  `json:"service_name" example:"Netflix"`
-	Price       int     `json:"price" example:"499"`
-	UserID      string  `json:"user_id" example:"e5c7c66b-4a3e-4728-84d9-b6c6b46ef1a6"`
-	StartDate   string  `json:"start_date" example:"07-2024"`
-	EndDate     *string `json:"end_date,omitempty" example:"12-2024"`
+	ID           string                `json:"id" example:"696c530f-b6c5-467f-ab70-45916e72daa7"`
+	ServiceName  string                `json:"service_name" example:"Netflix"`
+	ServiceID    *string               `json:"service_id,omitempty" example:"3f1c2a8e-7d7b-4c61-9d3e-2f0a4b5c6d7e"`
+	Price        int                   `json:"price" example:"499"`
+	UserID       string                `json:"user_id" example:"e5c7c66b-4a3e-4728-84d9-b6c6b46ef1a6"`
+	StartDate    string                `json:"start_date" example:"07-2024"`
+	EndDate      *string               `json:"end_date,omitempty" example:"12-2024"`
+	BillingDay   *int                  `json:"billing_day,omitempty" example:"15"`
+	TrialEndDate *string               `json:"trial_end_date,omitempty" example:"08-2024"`
+	Status       string                `json:"status" example:"active" enums:"upcoming,trial,active,paused,cancelled-pending,ended"`
+	Categories   []string              `json:"categories,omitempty" example:"streaming"`
+	Tags         []string              `json:"tags,omitempty" example:"family,work"`
+	Members      []MemberDTO           `json:"members,omitempty"`
+	Discounts    []DiscountResponseDTO `json:"discounts,omitempty"`
+	Cancellation *CancellationDTO      `json:"cancellation,omitempty"`
+	Pauses       []PauseDTO            `json:"pauses,omitempty"`
 }
 
 func (dto *SubscriptionRequestDTO) Validate() error {
-	if strings.TrimSpace(dto.ServiceName) == "" {
-		return errors.New("service_name is required")
+	if dto.ServiceID != nil {
+		if _, err := uuid.Parse(*dto.ServiceID); err != nil {
+			return errors.New("service_id is invalid UUID")
+		}
+	} else if strings.TrimSpace(dto.ServiceName) == "" {
+		return errors.New("service_name or service_id is required")
 	}
-	if dto.Price <= 0 {
-		return errors.New("price must be greater than 0")
+	// A zero price means "use the catalog default price".
+	if dto.Price < 0 {
+		return errors.New("price must not be negative")
 	}
 	if _, err := uuid.Parse(dto.UserID); err != nil {
 		return errors.New("user_id is invalid UUID")
 	}
-	if _, err := time.Parse("01-2006", dto.StartDate); err != nil {
-		return errors.New("start_date has invalid format, expected MM-YYYY")
+	startDate, err := domain.ParseYearMonth(dto.StartDate)
+	if err != nil {
+		return errors.New("start_date has invalid format, expected MM-YYYY or YYYY-MM-DD")
+	}
+	if dto.BillingDay != nil && (*dto.BillingDay < 1 || *dto.BillingDay > 31) {
+		return errors.New("billing_day must be between 1 and 31")
+	}
+	if err := validateLabels("categories", dto.Categories); err != nil {
+		return err
+	}
+	if err := validateLabels("tags", dto.Tags); err != nil {
+		return err
+	}
+	if err := validateMembers(dto.Members); err != nil {
+		return err
+	}
+	if dto.TrialEndDate != nil {
+		trialEnd, err := domain.ParseYearMonth(*dto.TrialEndDate)
+		if err != nil {
+			return errors.New("trial_end_date has invalid format, expected MM-YYYY or YYYY-MM-DD")
+		}
+		if trialEnd.Last().Before(startDate.First()) {
+			return errors.New("trial_end_date cannot be before start_date")
+		}
 	}
 	if dto.EndDate != nil {
-		endTime, err := time.Parse("01-2006", *dto.EndDate)
+		endDate, err := domain.ParseYearMonth(*dto.EndDate)
 		if err != nil {
-			return errors.New("end_date has invalid format, expected MM-YYYY")
+			return errors.New("end_date has invalid format, expected MM-YYYY or YYYY-MM-DD")
 		}
-		startTime, _ := time.Parse("01-2006", dto.StartDate)
-		if endTime.Before(startTime) {
+		if endDate.Last().Before(startDate.First()) {
 			return errors.New("end_date cannot be before start_date")
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// MemberDTO is a user sharing the subscription. Weight defaults to 1.
+type MemberDTO struct {
+	UserID string `json:"user_id" example:"0b7e4c1a-3f7e-4f8e-9a55-3c1d2e4f5a6b"`
+	Weight int    `json:"weight,omitempty" example:"1"`
+}
+
+type CostGroupDTO struct {
+	Key   string `json:"key" example:"streaming"`
+	Total int64  `json:"total" example:"1497"`
+}
+
+type TotalCostResponseDTO struct {
+	Total  int64          `json:"total" example:"2994"`
+	Groups []CostGroupDTO `json:"groups,omitempty"`
+}
+
+const maxLabelLength = 64
+
+func validateLabels(field string, labels []string) error {
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			return fmt.Errorf("%s must not contain empty values", field)
+		}
+		if len(label) > maxLabelLength {
+			return fmt.Errorf("%s values must be at most %d characters", field, maxLabelLength)
+		}
+	}
+	return nil
+}
+
+func validateMembers(members []MemberDTO) error {
+	seen := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		id, err := uuid.Parse(m.UserID)
+		if err != nil {
+			return errors.New("members.user_id is invalid UUID")
+		}
+		if seen[id] {
+			return errors.New("members must not contain the same user twice")
+		}
+		seen[id] = true
+		if m.Weight < 0 {
+			return errors.New("members.weight must be greater than 0")
+		}
+	}
+	return nil
+}
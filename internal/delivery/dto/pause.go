@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"errors"
+
+	"subscription-service/internal/domain"
+)
+
+// PauseRequestDTO suspends billing from start_date (default: today) until
+// end_date inclusive, or until resumed when end_date is omitted.
+type PauseRequestDTO struct {
+	StartDate *string `json:"start_date,omitempty" example:"2024-09-01"`
+	EndDate   *string `json:"end_date,omitempty" example:"2024-10-31"`
+}
+
+// ResumeRequestDTO restarts billing on date (default: today).
+type ResumeRequestDTO struct {
+	Date *string `json:"date,omitempty" example:"2024-11-01"`
+}
+
+type PauseDTO struct {
+	ID        string  `json:"id" example:"5d7c2b1a-9e8f-4a3b-b2c1-0f9e8d7c6b5a"`
+	StartDate string  `json:"start_date" example:"2024-09-01"`
+	EndDate   *string `json:"end_date,omitempty" example:"2024-10-31"`
+}
+
+func (dto *PauseRequestDTO) Validate() error {
+	var start domain.YearMonth
+	if dto.StartDate != nil {
+		var err error
+		if start, err = domain.ParseYearMonth(*dto.StartDate); err != nil {
+			return errors.New("start_date has invalid format, expected MM-YYYY or YYYY-MM-DD")
+		}
+	}
+	if dto.EndDate != nil {
+		end, err := domain.ParseYearMonth(*dto.EndDate)
+		if err != nil {
+			return errors.New("end_date has invalid format, expected MM-YYYY or YYYY-MM-DD")
+		}
+		if dto.StartDate != nil && end.Last().Before(start.First()) {
+			return errors.New("end_date cannot be before start_date")
+		}
+	}
+	return nil
+}
+
+func (dto *ResumeRequestDTO) Validate() error {
+	if dto.Date != nil {
+		if _, err := domain.ParseYearMonth(*dto.Date); err != nil {
+			return errors.New("date has invalid format, expected MM-YYYY or YYYY-MM-DD")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+)
+
+type ServiceRequestDTO struct {
+	Name         string   `json:"name" example:"Netflix"`
+	Aliases      []string `json:"aliases,omitempty" example:"netflix premium,nflx"`
+	Category     string   `json:"category,omitempty" example:"streaming"`
+	DefaultPrice *int     `json:"default_price,omitempty" example:"499"`
+}
+
+type ServiceResponseDTO struct {
+	ID           string   `json:"id" example:"3f1c2a8e-7d7b-4c61-9d3e-2f0a4b5c6d7e"`
+	Name         string   `json:"name" example:"Netflix"`
+	Aliases      []string `json:"aliases" example:"netflix premium,nflx"`
+	Category     string   `json:"category,omitempty" example:"streaming"`
+	DefaultPrice *int     `json:"default_price,omitempty" example:"499"`
+}
+
+func (dto *ServiceRequestDTO) Validate() error {
+	if strings.TrimSpace(dto.Name) == "" {
+		return errors.New("name is required")
+	}
+	if dto.DefaultPrice != nil && *dto.DefaultPrice <= 0 {
+		return errors.New("default_price must be greater than 0")
+	}
+	for _, alias := range dto.Aliases {
+		if strings.TrimSpace(alias) == "" {
+			return errors.New("aliases must not contain empty values")
+		}
+	}
+	return nil
+}
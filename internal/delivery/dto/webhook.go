@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+
+	"subscription-service/internal/domain"
+)
+
+// WebhookRequestDTO registers a webhook endpoint. A secret is generated when
+// none is given.
+type WebhookRequestDTO struct {
+	URL        string   `json:"url" example:"https://crm.example.com/hooks/subscriptions"`
+	Secret     string   `json:"secret,omitempty" example:"s3cr3t"`
+	EventTypes []string `json:"event_types" example:"subscription.created,subscription.cancelled"`
+}
+
+// WebhookResponseDTO describes an endpoint. The secret is only included in
+// the response to its creation.
+type WebhookResponseDTO struct {
+	ID         string   `json:"id" example:"5a0c6f7e-2b1d-4e8f-9a3b-7c6d5e4f3a2b"`
+	URL        string   `json:"url" example:"https://crm.example.com/hooks/subscriptions"`
+	Secret     string   `json:"secret,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	EventTypes []string `json:"event_types" example:"subscription.created,subscription.cancelled"`
+	CreatedAt  string   `json:"created_at" example:"2024-11-05T10:15:00Z"`
+}
+
+type WebhookDeliveryDTO struct {
+	ID             string          `json:"id" example:"0e4b7c2d-6a5f-4d3e-8b1a-9c8d7e6f5a4b"`
+	EventID        string          `json:"event_id" example:"7d9e1f2a-3b4c-4d5e-8f6a-1b2c3d4e5f6a"`
+	EventType      string          `json:"event_type" example:"subscription.created"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status" example:"delivered" enums:"pending,delivered,failed,dead"`
+	Attempts       int             `json:"attempts" example:"1"`
+	ResponseStatus *int            `json:"response_status,omitempty" example:"200"`
+	LastError      string          `json:"last_error,omitempty" example:"endpoint responded with status 503"`
+	NextAttemptAt  string          `json:"next_attempt_at" example:"2024-11-05T10:16:00Z"`
+	CreatedAt      string          `json:"created_at" example:"2024-11-05T10:15:00Z"`
+	DeliveredAt    *string         `json:"delivered_at,omitempty" example:"2024-11-05T10:15:01Z"`
+}
+
+func (dto *WebhookRequestDTO) Validate() error {
+	u, err := url.Parse(dto.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if len(dto.EventTypes) == 0 {
+		return errors.New("event_types must not be empty")
+	}
+	for _, t := range dto.EventTypes {
+		if !domain.EventType(t).Valid() {
+			return errors.New("event_types must contain only subscription.created, subscription.updated, subscription.deleted or subscription.cancelled")
+		}
+	}
+	return nil
+}
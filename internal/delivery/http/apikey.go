@@ -0,0 +1,126 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"subscription-service/internal/delivery/dto"
+	"subscription-service/internal/usecase/apikey"
+	dtoConv "subscription-service/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	service *apikey.Service
+	logger  *slog.Logger
+}
+
+func NewAPIKeyHandler(service *apikey.Service, logger *slog.Logger) *APIKeyHandler {
+	return &APIKeyHandler{service: service, logger: logger}
+}
+
+// Create godoc
+// @Summary Issue API key
+// @Description Issue a key for a service-to-service client, sent as "Authorization: ApiKey <key>". The key is only returned here.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param key body dto.APIKeyRequestDTO true "API key request"
+// @Success 201 {object} dto.APIKeyResponseDTO
+// @Failure 400 {string} string "invalid request"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("handling api key Create request")
+
+	var req dto.APIKeyRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	k := dtoConv.APIKeyRequestDtoToDomain(req)
+	secret, err := h.service.Issue(r.Context(), k)
+	if err != nil {
+		h.logger.Error("failed to issue api key", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	h.logger.Info("api key issued successfully", slog.String("id", k.ID.String()))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dtoConv.APIKeyToResponseDTO(k, secret))
+}
+
+// GetAll godoc
+// @Summary List API keys
+// @Description List issued API keys, including revoked and expired ones
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} dto.APIKeyResponseDTO
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("handling api key GetAll request")
+
+	keys, err := h.service.List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list api keys", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	result := make([]dto.APIKeyResponseDTO, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, dtoConv.APIKeyToResponseDTO(k, ""))
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// Revoke godoc
+// @Summary Revoke API key
+// @Description Revoke an API key; requests using it are rejected immediately
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {string} string "invalid id"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "not found"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.logger.Info("handling api key Revoke request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), id); err != nil {
+		h.logger.Error("failed to revoke api key", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message": "api key revoked successfully"}`))
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/domain"
+	"subscription-service/pkg/requestctx"
+)
+
+// TokenVerifier validates a bearer token and returns the caller identity.
+type TokenVerifier interface {
+	Verify(token string) (*auth.Identity, error)
+}
+
+// KeyAuthenticator resolves an API key to the identity of its client.
+type KeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*auth.Identity, error)
+}
+
+// Authenticator identifies callers by a JWT (`Authorization: Bearer ...`)
+// or an API key (`Authorization: ApiKey ...`).
+type Authenticator struct {
+	tokens TokenVerifier
+	keys   KeyAuthenticator
+	logger *slog.Logger
+}
+
+func NewAuthenticator(tokens TokenVerifier, keys KeyAuthenticator, logger *slog.Logger) *Authenticator {
+	return &Authenticator{tokens: tokens, keys: keys, logger: logger}
+}
+
+// Middleware rejects requests without valid credentials and stores the
+// caller identity in the request context. The identity subject is the actor
+// recorded in the audit log; X-Actor is ignored.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, credentials, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		credentials = strings.TrimSpace(credentials)
+		if !ok || credentials == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer`)
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		var (
+			identity *auth.Identity
+			err      error
+		)
+		switch {
+		case strings.EqualFold(scheme, "Bearer") && a.tokens != nil:
+			identity, err = a.tokens.Verify(credentials)
+		case strings.EqualFold(scheme, "ApiKey") && a.keys != nil:
+			identity, err = a.keys.Authenticate(r.Context(), credentials)
+		default:
+			w.Header().Set("WWW-Authenticate", `Bearer`)
+			http.Error(w, "unsupported authorization scheme", http.StatusUnauthorized)
+			return
+		}
+
+		if err != nil {
+			a.logger.Warn("rejected credentials", slog.String("scheme", scheme), slog.String("path", r.URL.Path), slog.String("error", err.Error()))
+			description := "invalid credentials"
+			switch {
+			case errors.Is(err, auth.ErrExpiredToken):
+				description = "token expired"
+			case !errors.Is(err, auth.ErrInvalidToken) && !errors.Is(err, domain.ErrUnauthorized):
+				http.Error(w, "authentication failed", http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description="`+description+`"`)
+			http.Error(w, description, http.StatusUnauthorized)
+			return
+		}
+
+		ctx := auth.WithIdentity(r.Context(), identity)
+		ctx = requestctx.WithActor(ctx, identity.Subject)
+		ctx = withLogAttrs(ctx, slog.String("user", identity.Subject))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requireScope limits a route to API keys granted scope. Other callers pass
+// through; their roles are checked by the usecase layer.
+func requireScope(scope domain.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if identity := auth.FromContext(r.Context()); identity != nil && !identity.Allows(scope) {
+				http.Error(w, "api key lacks scope "+string(scope), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"subscription-service/internal/delivery/dto"
+	"subscription-service/internal/domain"
+	dtoConv "subscription-service/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Cancel godoc
+// @Summary Cancel subscription
+// @Description Schedule the end of a subscription with a reason code; defaults to the end of the current billing period, or the start month for subscriptions that have not started; never moves an existing end_date later
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param cancellation body dto.CancelRequestDTO true "Cancellation request"
+// @Success 200 {object} dto.SubscriptionResponseDTO
+// @Failure 400 {string} string "invalid input"
+// @Failure 404 {string} string "not found"
+// @Failure 409 {string} string "already cancelled"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/cancel [post]
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.log(r.Context()).Info("handling Cancel request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.log(r.Context()).Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.CancelRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log(r.Context()).Warn("invalid request body", slog.String("error", err.Error()))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var effective *domain.YearMonth
+	if req.EffectiveMonth != nil {
+		ym, _ := domain.ParseYearMonth(*req.EffectiveMonth)
+		effective = &ym
+	}
+
+	sub, err := h.service.Cancel(r.Context(), id, effective, domain.CancelReason(req.Reason), strings.TrimSpace(req.Comment))
+	if err != nil {
+		h.log(r.Context()).Error("failed to cancel subscription", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	h.log(r.Context()).Info("subscription cancelled successfully", slog.String("id", idStr))
+	json.NewEncoder(w).Encode(dtoConv.DomainToResponseDTO(sub))
+}
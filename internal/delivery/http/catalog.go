@@ -0,0 +1,214 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"subscription-service/internal/delivery/dto"
+	"subscription-service/internal/usecase/catalog"
+	dtoConv "subscription-service/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type CatalogHandler struct {
+	service *catalog.Service
+	logger  *slog.Logger
+}
+
+func NewCatalogHandler(service *catalog.Service, logger *slog.Logger) *CatalogHandler {
+	return &CatalogHandler{service: service, logger: logger}
+}
+
+// Create godoc
+// @Summary Create catalog service
+// @Description Register a service with its canonical name, aliases, category and default price. Admins only.
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param service body dto.ServiceRequestDTO true "Service request"
+// @Success 201 {object} dto.ServiceResponseDTO
+// @Failure 400 {string} string "invalid request"
+// @Failure 403 {string} string "forbidden"
+// @Failure 409 {string} string "name or alias already taken"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /services [post]
+func (h *CatalogHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("handling catalog Create request")
+
+	var req dto.ServiceRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	svc := dtoConv.ServiceRequestDtoToDomain(req)
+	if err := h.service.Create(r.Context(), svc); err != nil {
+		h.logger.Error("failed to create service", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	h.logger.Info("service created successfully", slog.String("id", svc.ID.String()))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dtoConv.ServiceToResponseDTO(svc))
+}
+
+// GetAll godoc
+// @Summary Get all catalog services
+// @Description Get the service catalog with aliases
+// @Tags services
+// @Produce json
+// @Success 200 {array} dto.ServiceResponseDTO
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /services [get]
+func (h *CatalogHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("handling catalog GetAll request")
+
+	services, err := h.service.GetAll(r.Context())
+	if err != nil {
+		h.logger.Error("failed to get services", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]dto.ServiceResponseDTO, 0, len(services))
+	for _, svc := range services {
+		result = append(result, dtoConv.ServiceToResponseDTO(svc))
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetByID godoc
+// @Summary Get catalog service
+// @Description Get a catalog service by its ID
+// @Tags services
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {object} dto.ServiceResponseDTO
+// @Failure 400 {string} string "invalid id"
+// @Failure 404 {string} string "not found"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /services/{id} [get]
+func (h *CatalogHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.logger.Info("handling catalog GetByID request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	svc, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(dtoConv.ServiceToResponseDTO(svc))
+}
+
+// Update godoc
+// @Summary Update catalog service
+// @Description Replace name, aliases, category and default price of a catalog service. Admins only.
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "Service ID"
+// @Param service body dto.ServiceRequestDTO true "Service update"
+// @Success 200 {object} dto.ServiceResponseDTO
+// @Failure 400 {string} string "invalid input"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "not found"
+// @Failure 409 {string} string "name or alias already taken"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /services/{id} [put]
+func (h *CatalogHandler) Update(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.logger.Info("handling catalog Update request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.ServiceRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	svc := dtoConv.ServiceRequestDtoToDomain(req)
+	svc.ID = id
+
+	if err := h.service.Update(r.Context(), svc); err != nil {
+		h.logger.Error("failed to update service", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	h.logger.Info("service updated successfully", slog.String("id", idStr))
+	json.NewEncoder(w).Encode(dtoConv.ServiceToResponseDTO(svc))
+}
+
+// Delete godoc
+// @Summary Delete catalog service
+// @Description Delete a catalog service; linked subscriptions keep their service name. Admins only.
+// @Tags services
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {string} string "invalid id"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "not found"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /services/{id} [delete]
+func (h *CatalogHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.logger.Info("handling catalog Delete request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		h.logger.Error("failed to delete service", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message": "service deleted successfully"}`))
+}
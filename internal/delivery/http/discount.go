@@ -0,0 +1,149 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"subscription-service/internal/delivery/dto"
+	dtoConv "subscription-service/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// AddDiscount godoc
+// @Summary Add discount
+// @Description Attach a percentage or fixed discount valid for a month range to a subscription
+// @Tags discounts
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param discount body dto.DiscountRequestDTO true "Discount request"
+// @Success 201 {object} dto.DiscountResponseDTO
+// @Failure 400 {string} string "invalid input"
+// @Failure 404 {string} string "subscription not found"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/discounts [post]
+func (h *Handler) AddDiscount(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.log(r.Context()).Info("handling AddDiscount request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.log(r.Context()).Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.DiscountRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log(r.Context()).Warn("invalid request body", slog.String("error", err.Error()))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	discount, err := dtoConv.DiscountRequestDtoToDomain(id, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.AddDiscount(r.Context(), discount); err != nil {
+		h.log(r.Context()).Error("failed to add discount", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	h.log(r.Context()).Info("discount added successfully", slog.String("id", discount.ID.String()))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dtoConv.DiscountToResponseDTO(*discount))
+}
+
+// ListDiscounts godoc
+// @Summary List discounts
+// @Description List the discounts attached to a subscription
+// @Tags discounts
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {array} dto.DiscountResponseDTO
+// @Failure 400 {string} string "invalid id"
+// @Failure 404 {string} string "subscription not found"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/discounts [get]
+func (h *Handler) ListDiscounts(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.log(r.Context()).Info("handling ListDiscounts request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.log(r.Context()).Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	discounts, err := h.service.ListDiscounts(r.Context(), id)
+	if err != nil {
+		h.log(r.Context()).Error("failed to list discounts", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	result := make([]dto.DiscountResponseDTO, 0, len(discounts))
+	for _, d := range discounts {
+		result = append(result, dtoConv.DiscountToResponseDTO(d))
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// DeleteDiscount godoc
+// @Summary Delete discount
+// @Description Remove a discount from a subscription
+// @Tags discounts
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param discountID path string true "Discount ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {string} string "invalid id"
+// @Failure 404 {string} string "not found"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/discounts/{discountID} [delete]
+func (h *Handler) DeleteDiscount(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	discountIDStr := chi.URLParam(r, "discountID")
+	h.log(r.Context()).Info("handling DeleteDiscount request", slog.String("id", idStr), slog.String("discount_id", discountIDStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.log(r.Context()).Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	discountID, err := uuid.Parse(discountIDStr)
+	if err != nil {
+		h.log(r.Context()).Warn("invalid UUID", slog.String("discount_id", discountIDStr))
+		http.Error(w, "invalid discount id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteDiscount(r.Context(), id, discountID); err != nil {
+		h.log(r.Context()).Error("failed to delete discount", slog.String("discount_id", discountIDStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message": "discount deleted successfully"}`))
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"subscription-service/internal/domain"
+)
+
+// errorStatus maps usecase errors onto HTTP status codes. Anything not
+// recognised is treated as an internal error.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrUnknownService),
+		errors.Is(err, domain.ErrPriceRequired),
+		errors.Is(err, domain.ErrCancelBeforeStart),
+		errors.Is(err, domain.ErrCancelAfterEnd),
+		errors.Is(err, domain.ErrPauseEndsEarly):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrDuplicateName),
+		errors.Is(err, domain.ErrDuplicateAlias),
+		errors.Is(err, domain.ErrAlreadyCancelled),
+		errors.Is(err, domain.ErrCancelledEndDate),
+		errors.Is(err, domain.ErrAlreadyPaused),
+		errors.Is(err, domain.ErrNotPaused),
+		errors.Is(err, domain.ErrNotRunning):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"subscription-service/internal/usecase/feed"
+
+	"github.com/google/uuid"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+// streamWriteTimeout bounds each write to an event stream. It is longer than
+// heartbeatInterval, so it never runs out between writes to a client that
+// keeps reading.
+const streamWriteTimeout = 2 * heartbeatInterval
+
+type FeedHandler struct {
+	service *feed.Service
+	logger  *slog.Logger
+
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+func NewFeedHandler(service *feed.Service, logger *slog.Logger) *FeedHandler {
+	return &FeedHandler{service: service, logger: logger, closing: make(chan struct{})}
+}
+
+// Shutdown ends every open event stream. Streams never go idle, so the
+// server calls it on shutdown to let them finish instead of waiting for
+// the drain deadline; clients reconnect with Last-Event-ID elsewhere.
+func (h *FeedHandler) Shutdown() {
+	h.closeOnce.Do(func() { close(h.closing) })
+}
+
+// Events godoc
+// @Summary Subscription change feed
+// @Description Stream subscription events as Server-Sent Events. Each event has the log sequence number as id, the event type as event and the event JSON as data. Events appear once the outbox relay has handled them, in that order, so reconnecting with Last-Event-ID (or last_event_id) resumes without gaps; without it only new events are sent.
+// @Tags subscriptions
+// @Produce text/event-stream
+// @Param user_id query string false "Only events about subscriptions the user owns or shares"
+// @Param last_event_id query int false "Resume after this sequence number, for clients that cannot set Last-Event-ID"
+// @Param Last-Event-ID header int false "Resume after this sequence number"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {string} string "invalid input"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/events [get]
+func (h *FeedHandler) Events(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("handling Events request")
+
+	var userID *uuid.UUID
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		id, err := uuid.Parse(userIDStr)
+		if err != nil {
+			h.logger.Warn("invalid user_id", slog.String("user_id", userIDStr))
+			http.Error(w, "invalid user_id", http.StatusBadRequest)
+			return
+		}
+		userID = &id
+	}
+
+	var after *int64
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	if lastEventID != "" {
+		seq, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || seq < 0 {
+			h.logger.Warn("invalid Last-Event-ID", slog.String("value", lastEventID))
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		after = &seq
+	}
+
+	events, err := h.service.Watch(r.Context(), after, userID)
+	if err != nil {
+		h.logger.Error("failed to open event feed", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The stream outlives the server write timeout, which is meant for
+	// ordinary requests, so each write gets a deadline of its own instead.
+	// A client that stops reading is dropped once a write runs past it.
+	extendDeadline := func() bool {
+		err := rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			h.logger.Error("failed to set write deadline", slog.String("error", err.Error()))
+			return false
+		}
+		return true
+	}
+	if !extendDeadline() {
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		h.logger.Error("event feed requires a flushable response", slog.String("error", err.Error()))
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			h.logger.Info("event feed closed by client")
+			return
+		case <-h.closing:
+			h.logger.Info("event feed closed for shutdown")
+			return
+		case <-heartbeat.C:
+			if !extendDeadline() {
+				return
+			}
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				h.logger.Warn("event feed ended")
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger.Error("failed to encode event", slog.String("error", err.Error()))
+				return
+			}
+			if !extendDeadline() {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
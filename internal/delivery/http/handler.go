@@ -1,11 +1,15 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
+	"subscription-service/internal/domain"
 	"subscription-service/internal/usecase/subscription"
 	"subscription-service/internal/delivery/dto"
 	dtoConv "subscription-service/pkg/utils"
@@ -22,6 +26,13 @@ type Handler struct {
 func NewHandler(service *subscription.Service, logger *slog.Logger) *Handler {
 	return &Handler{service: service, logger: logger}
 }
+
+// log returns the request logger, which tags lines with the request ID,
+// route, user and tenant.
+func (h *Handler) log(ctx context.Context) *slog.Logger {
+	return requestLogger(ctx, h.logger)
+}
+
 // Create godoc
 // @Summary Create subscription
 // @Description Create a new subscription
@@ -32,13 +43,15 @@ func NewHandler(service *subscription.Service, logger *slog.Logger) *Handler {
 // @Success 201 {object} dto.SubscriptionResponseDTO
 // @Failure 400 {string} string "invalid request"
 // @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions [post]
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	h.logger.Info("handling Create request")
+	h.log(r.Context()).Info("handling Create request")
 
 	var req dto.SubscriptionRequestDTO
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		h.log(r.Context()).Warn("invalid request body", slog.String("error", err.Error()))
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
@@ -55,66 +68,111 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 	sub.ID = uuid.New()
 
-	h.logger.Debug("creating subscription", slog.Any("subscription", sub))
+	h.log(r.Context()).Debug("creating subscription", slog.Any("subscription", sub))
 
 	if err := h.service.Create(r.Context(), sub); err != nil {
-		h.logger.Error("failed to create subscription", slog.String("error", err.Error()))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.log(r.Context()).Error("failed to create subscription", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
-	h.logger.Info("subscription created successfully", slog.String("id", sub.ID.String()))
+	h.log(r.Context()).Info("subscription created successfully", slog.String("id", sub.ID.String()))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(dtoConv.DomainToResponseDTO(sub))
 }
 
 // GetAll godoc
 // @Summary Get all subscriptions
-// @Description Get list of all subscriptions
+// @Description Get list of all subscriptions, optionally filtered by user, service, categories and tags
 // @Tags subscriptions
 // @Produce json
+// @Param user_id query string false "User UUID, matches owners and members"
+// @Param service_name query string false "Service name or any of its catalog aliases"
+// @Param category query []string false "Match any of the categories" collectionFormat(multi)
+// @Param tag query []string false "Match all of the tags" collectionFormat(multi)
+// @Param status query string false "Lifecycle status as of as_of" Enums(upcoming, trial, active, paused, cancelled-pending, ended)
+// @Param as_of query string false "Day (YYYY-MM-DD) or last day of month (MM-YYYY) the status is derived for, defaults to today; an RFC 3339 timestamp returns the state recorded at that moment"
 // @Success 200 {array} dto.SubscriptionResponseDTO
+// @Failure 400 {string} string "invalid input"
 // @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions [get]
 func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
-	h.logger.Info("handling GetAll request")
+	h.log(r.Context()).Info("handling GetAll request")
 
-	subs, err := h.service.GetAll(r.Context())
+	query := r.URL.Query()
+	filter, err := parseFilter(query)
 	if err != nil {
-		h.logger.Error("failed to get all subscriptions", slog.String("error", err.Error()))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.log(r.Context()).Warn("invalid filter", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter.AsOf, filter.RecordedAt, err = parseAsOf(query)
+	if err != nil {
+		h.log(r.Context()).Warn("invalid as_of", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if status := domain.Status(query.Get("status")); status != "" {
+		if !status.Valid() {
+			h.log(r.Context()).Warn("invalid status", slog.String("value", string(status)))
+			http.Error(w, "invalid status, expected upcoming, trial, active, paused, cancelled-pending or ended", http.StatusBadRequest)
+			return
+		}
+		filter.Status = status
+	}
+
+	subs, err := h.service.GetAll(r.Context(), filter)
+	if err != nil {
+		h.log(r.Context()).Error("failed to get all subscriptions", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
 	var result []dto.SubscriptionResponseDTO
 	for _, sub := range subs {
-		result = append(result, dtoConv.DomainToResponseDTO(sub))
+		result = append(result, dtoConv.DomainToResponseDTOAsOf(sub, filter.AsOf))
 	}
 
-	h.logger.Info("subscriptions retrieved", slog.Int("count", len(result)))
+	h.log(r.Context()).Info("subscriptions retrieved", slog.Int("count", len(result)))
 	json.NewEncoder(w).Encode(result)
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
-	h.logger.Info("handling GetByID request", slog.String("id", idStr))
+	h.log(r.Context()).Info("handling GetByID request", slog.String("id", idStr))
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		h.log(r.Context()).Warn("invalid UUID", slog.String("id", idStr))
 		http.Error(w, "invalid id", http.StatusBadRequest)
 		return
 	}
 
-	sub, err := h.service.GetByID(r.Context(), id)
+	asOf, recordedAt, err := parseAsOf(r.URL.Query())
 	if err != nil {
-		h.logger.Error("subscription not found", slog.String("id", idStr), slog.String("error", err.Error()))
-		http.Error(w, err.Error(), http.StatusNotFound)
+		h.log(r.Context()).Warn("invalid as_of", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	h.logger.Info("subscription found", slog.String("id", idStr))
-	json.NewEncoder(w).Encode(dtoConv.DomainToResponseDTO(sub))
+	var sub *domain.Subscription
+	if recordedAt != nil {
+		sub, err = h.service.GetByIDAt(r.Context(), id, *recordedAt)
+	} else {
+		sub, err = h.service.GetByID(r.Context(), id)
+	}
+	if err != nil {
+		h.log(r.Context()).Error("subscription not found", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	h.log(r.Context()).Info("subscription found", slog.String("id", idStr))
+	json.NewEncoder(w).Encode(dtoConv.DomainToResponseDTOAsOf(sub, asOf))
 }
 
 // Update godoc
@@ -127,22 +185,25 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 // @Param subscription body dto.SubscriptionRequestDTO true "Subscription update"
 // @Success 200 {object} dto.SubscriptionResponseDTO
 // @Failure 400 {string} string "invalid input"
+// @Failure 409 {string} string "end_date of a cancelled subscription changed"
 // @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions/{id} [put]
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
-	h.logger.Info("handling Update request", slog.String("id", idStr))
+	h.log(r.Context()).Info("handling Update request", slog.String("id", idStr))
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		h.log(r.Context()).Warn("invalid UUID", slog.String("id", idStr))
 		http.Error(w, "invalid id", http.StatusBadRequest)
 		return
 	}
 
 	var req dto.SubscriptionRequestDTO
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		h.log(r.Context()).Warn("invalid request body", slog.String("error", err.Error()))
 		http.Error(w, "invalid request", http.StatusBadRequest)
 		return
 	}
@@ -159,15 +220,15 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	sub.ID = id
 
-	h.logger.Debug("updating subscription", slog.Any("subscription", sub))
+	h.log(r.Context()).Debug("updating subscription", slog.Any("subscription", sub))
 
 	if err := h.service.Update(r.Context(), sub); err != nil {
-		h.logger.Error("failed to update subscription", slog.String("id", id.String()), slog.String("error", err.Error()))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.log(r.Context()).Error("failed to update subscription", slog.String("id", id.String()), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
-	h.logger.Info("subscription updated successfully", slog.String("id", id.String()))
+	h.log(r.Context()).Info("subscription updated successfully", slog.String("id", id.String()))
 	json.NewEncoder(w).Encode(dtoConv.DomainToResponseDTO(sub))
 }
 
@@ -180,25 +241,27 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} map[string]string
 // @Failure 400 {string} string "invalid id"
 // @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions/{id} [delete]
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
-	h.logger.Info("handling Delete request", slog.String("id", idStr))
+	h.log(r.Context()).Info("handling Delete request", slog.String("id", idStr))
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		h.log(r.Context()).Warn("invalid UUID", slog.String("id", idStr))
 		http.Error(w, "invalid id", http.StatusBadRequest)
 		return
 	}
 
 	if err := h.service.Delete(r.Context(), id); err != nil {
-		h.logger.Error("failed to delete subscription", slog.String("id", id.String()), slog.String("error", err.Error()))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.log(r.Context()).Error("failed to delete subscription", slog.String("id", id.String()), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
-	h.logger.Info("subscription deleted successfully", slog.String("id", id.String()))
+	h.log(r.Context()).Info("subscription deleted successfully", slog.String("id", id.String()))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -207,69 +270,198 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 // TotalCost godoc
 // @Summary Calculate total subscription cost
-// @Description Calculate total subscription cost in date range, optional filters by user and service
+// @Description Calculate what the matching subscriptions bill for every month in the date range (inclusive) while they are active, after discounts. Earlier versions summed the price of the subscriptions starting in the range once each; see CHANGELOG.md.
 // @Tags subscriptions
 // @Produce json
-// @Param from query string true "Start month in MM-YYYY format"
-// @Param to query string true "End month in MM-YYYY format"
-// @Param user_id query string false "User UUID"
-// @Param service_name query string false "Service name"
-// @Success 200 {object} map[string]int64
+// @Param from query string true "Start of the period, MM-YYYY or YYYY-MM-DD"
+// @Param to query string true "End of the period (inclusive), MM-YYYY or YYYY-MM-DD"
+// @Param proration query string false "How partial months are billed, defaults to none" Enums(none, daily)
+// @Param user_id query string false "User UUID; counts only the user's weighted share of shared subscriptions"
+// @Param service_name query string false "Service name or any of its catalog aliases"
+// @Param category query []string false "Match any of the categories" collectionFormat(multi)
+// @Param tag query []string false "Match all of the tags" collectionFormat(multi)
+// @Param group_by query string false "Split the total by category, tag or service" Enums(category, tag, service)
+// @Param as_of query string false "RFC 3339 timestamp; compute from the subscriptions as recorded at that moment so closed periods stay reproducible"
+// @Success 200 {object} dto.TotalCostResponseDTO
 // @Failure 400 {string} string "invalid input"
 // @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions/total [get]
 func (h *Handler) TotalCost(w http.ResponseWriter, r *http.Request) {
-	h.logger.Info("handling TotalCost request")
+	h.log(r.Context()).Info("handling TotalCost request")
 
 	query := r.URL.Query()
 	fromStr := query.Get("from")
 	toStr := query.Get("to")
 
-	from, err := time.Parse("01-2006", fromStr)
+	from, err := domain.ParseYearMonth(fromStr)
 	if err != nil {
-		h.logger.Warn("invalid 'from' date", slog.String("value", fromStr))
-		http.Error(w, "invalid from date format, expected MM-YYYY", http.StatusBadRequest)
+		h.log(r.Context()).Warn("invalid 'from' date", slog.String("value", fromStr))
+		http.Error(w, "invalid from date format, expected MM-YYYY or YYYY-MM-DD", http.StatusBadRequest)
 		return
 	}
 
-	to, err := time.Parse("01-2006", toStr)
+	to, err := domain.ParseYearMonth(toStr)
 	if err != nil {
-		h.logger.Warn("invalid 'to' date", slog.String("value", toStr))
-		http.Error(w, "invalid to date format, expected MM-YYYY", http.StatusBadRequest)
+		h.log(r.Context()).Warn("invalid 'to' date", slog.String("value", toStr))
+		http.Error(w, "invalid to date format, expected MM-YYYY or YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	period := domain.NewPeriod(from, to)
+
+	proration := domain.ProrationNone
+	switch p := domain.Proration(query.Get("proration")); p {
+	case "", domain.ProrationNone:
+	case domain.ProrationDaily:
+		proration = p
+	default:
+		h.log(r.Context()).Warn("invalid proration", slog.String("value", string(p)))
+		http.Error(w, "invalid proration, expected none or daily", http.StatusBadRequest)
 		return
 	}
 
-	var userID *uuid.UUID
-	if userIDStr := query.Get("user_id"); userIDStr != "" {
-		uid, err := uuid.Parse(userIDStr)
+	filter, err := parseFilter(query)
+	if err != nil {
+		h.log(r.Context()).Warn("invalid filter", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if asOfStr := query.Get("as_of"); asOfStr != "" {
+		at, err := time.Parse(time.RFC3339, asOfStr)
 		if err != nil {
-			h.logger.Warn("invalid user_id", slog.String("value", userIDStr))
-			http.Error(w, "invalid user_id", http.StatusBadRequest)
+			h.log(r.Context()).Warn("invalid as_of", slog.String("value", asOfStr))
+			http.Error(w, "invalid as_of, expected an RFC 3339 timestamp", http.StatusBadRequest)
 			return
 		}
-		userID = &uid
+		filter.RecordedAt = &at
 	}
 
-	var serviceName *string
-	if sn := query.Get("service_name"); sn != "" {
-		serviceName = &sn
+	var groupBy domain.CostGroupBy
+	switch gb := domain.CostGroupBy(query.Get("group_by")); gb {
+	case "", domain.GroupByCategory, domain.GroupByTag, domain.GroupByService:
+		groupBy = gb
+	default:
+		h.log(r.Context()).Warn("invalid group_by", slog.String("value", string(gb)))
+		http.Error(w, "invalid group_by, expected category, tag or service", http.StatusBadRequest)
+		return
 	}
 
-	h.logger.Debug("calculating total cost", slog.String("from", fromStr), slog.String("to", toStr))
+	h.log(r.Context()).Debug("calculating total cost", slog.String("from", fromStr), slog.String("to", toStr))
 
 	total, err := h.service.TotalCost(
 		r.Context(),
-		userID,
-		serviceName,
-		from,
-		to,
+		filter,
+		period,
+		proration,
 	)
 	if err != nil {
-		h.logger.Error("failed to calculate total cost", slog.String("error", err.Error()))
+		h.log(r.Context()).Error("failed to calculate total cost", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	resp := dto.TotalCostResponseDTO{Total: total}
+	if groupBy != "" {
+		groups, err := h.service.TotalCostByGroup(r.Context(), filter, groupBy, period, proration)
+		if err != nil {
+			h.log(r.Context()).Error("failed to calculate grouped total cost", slog.String("error", err.Error()))
+			http.Error(w, err.Error(), errorStatus(err))
+			return
+		}
+		resp.Groups = dtoConv.CostGroupsToDTO(groups)
+	}
+
+	h.log(r.Context()).Info("total cost calculated successfully", slog.Int64("total", total))
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Categories godoc
+// @Summary List categories
+// @Description List all known subscription categories
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {array} string
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /categories [get]
+func (h *Handler) Categories(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("handling Categories request")
+
+	categories, err := h.service.ListCategories(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("failed to list categories", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(categories)
+}
+
+// Tags godoc
+// @Summary List tags
+// @Description List all tags used on subscriptions
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {array} string
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tags [get]
+func (h *Handler) Tags(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("handling Tags request")
+
+	tags, err := h.service.ListTags(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("failed to list tags", slog.String("error", err.Error()))
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	h.logger.Info("total cost calculated successfully", slog.Int64("total", total))
-	json.NewEncoder(w).Encode(map[string]int64{"total": total})
+	json.NewEncoder(w).Encode(tags)
+}
+
+// parseFilter reads the user_id, service_name, category and tag query
+// parameters shared by the listing and cost endpoints.
+func parseFilter(query url.Values) (domain.SubscriptionFilter, error) {
+	var filter domain.SubscriptionFilter
+
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		uid, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return filter, errors.New("invalid user_id")
+		}
+		filter.UserID = &uid
+	}
+
+	if sn := query.Get("service_name"); sn != "" {
+		filter.ServiceName = &sn
+	}
+
+	filter.Categories = query["category"]
+	filter.Tags = query["tag"]
+	return filter, nil
+}
+
+// parseAsOf reads the as_of query parameter. A date (MM-YYYY or YYYY-MM-DD)
+// is the day status is derived for, defaulting to today. An RFC 3339
+// timestamp additionally selects the state recorded at that moment, with
+// status derived for its date.
+func parseAsOf(query url.Values) (domain.YearMonth, *time.Time, error) {
+	asOfStr := query.Get("as_of")
+	if asOfStr == "" {
+		return domain.Today(), nil, nil
+	}
+	if at, err := time.Parse(time.RFC3339, asOfStr); err == nil {
+		day := at.UTC()
+		asOf := domain.YearMonth{Time: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC), HasDay: true}
+		return asOf, &at, nil
+	}
+	asOf, err := domain.ParseYearMonth(asOfStr)
+	if err != nil {
+		return asOf, nil, errors.New("invalid as_of format, expected MM-YYYY, YYYY-MM-DD or an RFC 3339 timestamp")
+	}
+	return asOf, nil, nil
 }
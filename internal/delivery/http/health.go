@@ -0,0 +1,54 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"subscription-service/internal/health"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+	logger  *slog.Logger
+}
+
+func NewHealthHandler(checker *health.Checker, logger *slog.Logger) *HealthHandler {
+	return &HealthHandler{checker: checker, logger: logger}
+}
+
+// Liveness godoc
+// @Summary Liveness probe
+// @Description Reports that the process is running. It checks no dependencies, so a failing database does not get the service restarted.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Checks the database connection, the schema migration version and the background workers, each with a timeout, and reports the result of every check.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Run(r.Context())
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		h.logger.Warn("readiness check failed", slog.Any("checks", report.Checks))
+		status = http.StatusServiceUnavailable
+	}
+	writeReport(w, status, report)
+}
+
+func writeReport(w http.ResponseWriter, status int, report health.Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
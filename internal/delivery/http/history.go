@@ -0,0 +1,51 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"subscription-service/internal/delivery/dto"
+	dtoConv "subscription-service/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// History godoc
+// @Summary Subscription history
+// @Description List every recorded change to a subscription, oldest first, with the actor, request ID, before/after snapshots and changed fields. Available after deletion too.
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {array} dto.AuditEntryDTO
+// @Failure 400 {string} string "invalid id"
+// @Failure 404 {string} string "not found"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/history [get]
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.log(r.Context()).Info("handling History request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.log(r.Context()).Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.service.History(r.Context(), id)
+	if err != nil {
+		h.log(r.Context()).Error("failed to get subscription history", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	result := make([]dto.AuditEntryDTO, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, dtoConv.AuditEntryToDTO(e))
+	}
+	json.NewEncoder(w).Encode(result)
+}
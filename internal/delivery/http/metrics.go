@@ -0,0 +1,92 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RequestObserver records served requests.
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// MetricsHandler measures every request and serves the metrics, guarded by
+// a bearer token when one is configured.
+type MetricsHandler struct {
+	observer RequestObserver
+	metrics  http.Handler
+	token    string
+}
+
+func NewMetricsHandler(observer RequestObserver, metrics http.Handler, token string) *MetricsHandler {
+	return &MetricsHandler{observer: observer, metrics: metrics, token: token}
+}
+
+// Middleware records the method, route pattern, status and latency of each
+// request. Requests matching no route are grouped under "unmatched" and
+// non-standard methods under "OTHER".
+func (m *MetricsHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		m.observer.ObserveRequest(methodLabel(r.Method), route, sw.status, time.Since(start))
+	})
+}
+
+// methodLabel keeps the method label bounded: the method is chosen by the
+// client, so only the standard ones get a series of their own.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
+
+func (m *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if m.token != "" {
+		expected := "Bearer " + m.token
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer`)
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+	}
+	m.metrics.ServeHTTP(w, r)
+}
+
+// statusWriter remembers the response status. Unwrap keeps flushing and
+// deadlines working through http.ResponseController, which the event feed
+// relies on.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
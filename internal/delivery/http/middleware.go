@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"subscription-service/pkg/logger"
+	"subscription-service/pkg/requestctx"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	headerRequestID = "X-Request-ID"
+	headerActor     = "X-Actor"
+)
+
+// unauthenticatedActor prefixes actors taken from X-Actor, which any caller
+// can set, so the audit log does not pass them off as verified users.
+const unauthenticatedActor = "unauthenticated:"
+
+// requestContext accepts the caller's X-Request-ID or generates one, echoes
+// it in the response and stores it in the request context. It also stores a
+// logger derived from base that tags every line with the request ID and
+// method; later middleware adds the user, tenant and route. With actorHeader
+// set, for deployments without authentication, the acting user is taken
+// from X-Actor, marked as unauthenticated, so changes can be attributed in
+// the audit log; otherwise the authenticator sets it.
+func requestContext(base *slog.Logger, actorHeader bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(headerRequestID)
+			if requestID == "" || len(requestID) > 128 {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(headerRequestID, requestID)
+
+			ctx := requestctx.WithRequestID(r.Context(), requestID)
+			l := base.With(
+				slog.String("request_id", requestID),
+				slog.String("method", r.Method),
+			)
+			if actor := r.Header.Get(headerActor); actorHeader && actor != "" {
+				actor = unauthenticatedActor + actor
+				ctx = requestctx.WithActor(ctx, actor)
+				l = l.With(slog.String("user", actor))
+			}
+			ctx = logger.WithContext(ctx, l)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// logRoute adds the matched route pattern to the request logger. It must
+// run as an inline middleware of the route so chi has matched it already.
+func logRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if rctx := chi.RouteContext(ctx); rctx != nil {
+			ctx = withLogAttrs(ctx, slog.String("route", rctx.RoutePattern()))
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withLogAttrs adds attrs to the request logger stored in ctx. Without a
+// request logger ctx is returned unchanged.
+func withLogAttrs(ctx context.Context, attrs ...any) context.Context {
+	l := logger.FromContext(ctx, nil)
+	if l == nil {
+		return ctx
+	}
+	return logger.WithContext(ctx, l.With(attrs...))
+}
+
+// requestLogger returns the request logger stored in ctx, or fallback.
+func requestLogger(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	return logger.FromContext(ctx, fallback)
+}
+
+// MaxBodySize rejects request bodies larger than limit bytes. Bodies that
+// announce a larger Content-Length get 413 right away; others fail to
+// decode once they pass the limit. A limit that is not positive disables
+// the check.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
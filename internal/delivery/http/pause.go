@@ -0,0 +1,132 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"subscription-service/internal/delivery/dto"
+	"subscription-service/internal/domain"
+	dtoConv "subscription-service/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Pause godoc
+// @Summary Pause subscription
+// @Description Suspend billing from start_date (default: today) until end_date, or until resumed
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param pause body dto.PauseRequestDTO false "Pause request"
+// @Success 200 {object} dto.SubscriptionResponseDTO
+// @Failure 400 {string} string "invalid input"
+// @Failure 404 {string} string "not found"
+// @Failure 409 {string} string "already paused or ended"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/pause [post]
+func (h *Handler) Pause(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.log(r.Context()).Info("handling Pause request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.log(r.Context()).Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.PauseRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.log(r.Context()).Warn("invalid request body", slog.String("error", err.Error()))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var start, until *domain.YearMonth
+	if req.StartDate != nil {
+		ym, _ := domain.ParseYearMonth(*req.StartDate)
+		start = &ym
+	}
+	if req.EndDate != nil {
+		ym, _ := domain.ParseYearMonth(*req.EndDate)
+		until = &ym
+	}
+
+	sub, err := h.service.Pause(r.Context(), id, start, until)
+	if err != nil {
+		h.log(r.Context()).Error("failed to pause subscription", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	h.log(r.Context()).Info("subscription paused successfully", slog.String("id", idStr))
+	json.NewEncoder(w).Encode(dtoConv.DomainToResponseDTO(sub))
+}
+
+// Resume godoc
+// @Summary Resume subscription
+// @Description End the open pause so that billing restarts on date (default: today)
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param resume body dto.ResumeRequestDTO false "Resume request"
+// @Success 200 {object} dto.SubscriptionResponseDTO
+// @Failure 400 {string} string "invalid input"
+// @Failure 404 {string} string "not found"
+// @Failure 409 {string} string "not paused"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/resume [post]
+func (h *Handler) Resume(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.log(r.Context()).Info("handling Resume request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.log(r.Context()).Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.ResumeRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.log(r.Context()).Warn("invalid request body", slog.String("error", err.Error()))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var on *domain.YearMonth
+	if req.Date != nil {
+		ym, _ := domain.ParseYearMonth(*req.Date)
+		on = &ym
+	}
+
+	sub, err := h.service.Resume(r.Context(), id, on)
+	if err != nil {
+		h.log(r.Context()).Error("failed to resume subscription", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	h.log(r.Context()).Info("subscription resumed successfully", slog.String("id", idStr))
+	json.NewEncoder(w).Encode(dtoConv.DomainToResponseDTO(sub))
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/ratelimit"
+	"subscription-service/pkg/requestctx"
+)
+
+// RateLimiter applies token-bucket limits per client and route group.
+// Clients are told apart by tenant and API key or token subject or, for
+// anonymous requests, remote IP address.
+type RateLimiter struct {
+	store    ratelimit.Store
+	fallback ratelimit.Limit
+	groups   map[string]ratelimit.Limit
+	logger   *slog.Logger
+}
+
+// NewRateLimiter limits route groups without an entry in groups to
+// fallback. Groups with an invalid limit are not limited.
+func NewRateLimiter(store ratelimit.Store, fallback ratelimit.Limit, groups map[string]ratelimit.Limit, logger *slog.Logger) *RateLimiter {
+	return &RateLimiter{store: store, fallback: fallback, groups: groups, logger: logger}
+}
+
+// Group returns the middleware limiting the routes of group. Each client
+// has a separate bucket per group. A nil RateLimiter limits nothing.
+func (l *RateLimiter) Group(group string) func(http.Handler) http.Handler {
+	if l == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	limit, ok := l.groups[group]
+	if !ok {
+		limit = l.fallback
+	}
+	if !limit.Valid() {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := clientKey(r)
+			res, err := l.store.Take(r.Context(), group+":"+client, limit)
+			if err != nil {
+				// A broken store must not take the API down with it.
+				l.logger.Error("rate limit check failed", slog.String("group", group), slog.String("error", err.Error()))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+			if !res.Allowed {
+				l.logger.Warn("rate limit exceeded", slog.String("group", group), slog.String("client", client), slog.String("path", r.URL.Path))
+				h.Set("Retry-After", strconv.Itoa(seconds(res.RetryAfter)))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientKey identifies the caller. It runs after authentication and tenant
+// resolution, so the identity and its tenant are known whenever
+// authentication is enabled; the same subject in two tenants gets two
+// buckets. Anonymous callers are keyed by the address of the TCP peer
+// only: the tenant header is theirs to choose, and X-Forwarded-For is not
+// trusted, so behind a proxy they all share the proxy's bucket.
+func clientKey(r *http.Request) string {
+	if identity := auth.FromContext(r.Context()); identity != nil {
+		tenant := requestctx.Tenant(r.Context())
+		if identity.APIKey {
+			return tenant + ":" + identity.Subject
+		}
+		return tenant + ":user:" + identity.Subject
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// seconds rounds d up to whole seconds, as the headers require.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
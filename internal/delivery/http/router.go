@@ -3,41 +3,110 @@ package http
 import (
 	"net/http"
 	"time"
+	"subscription-service/internal/domain"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"github.com/go-chi/chi/v5"
 	"log/slog"
 )
 
-func NewRouter(h *Handler, logger *slog.Logger) http.Handler {
+// NewRouter wires the API routes. With a nil authenticator authentication
+// is disabled and every route is public. Each route names the API key scope
+// it needs; webhooks and API key management are refused to API keys by
+// the policy in the usecase layer. Every API route acts for the tenant
+// resolved by tenants and is rate limited per client by limiter in one of
+// the groups read, write, reports and admin; a nil limiter disables limits.
+// The health probes, metrics and Swagger stay public and unlimited. A nil
+// metrics handler disables metrics.
+func NewRouter(h *Handler, ch *CatalogHandler, wh *WebhookHandler, fh *FeedHandler, kh *APIKeyHandler, hh *HealthHandler, mh *MetricsHandler, authn *Authenticator, tenants *TenantResolver, limiter *RateLimiter, logger *slog.Logger) http.Handler {
 	r := chi.NewRouter()
 
+	r.Use(requestContext(logger, authn == nil))
+	r.Use(tracing)
+	if mh != nil {
+		r.Use(mh.Middleware)
+	}
 	r.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
-			logger.Info("HTTP request started",
-				"method", r.Method,
+			log := requestLogger(r.Context(), logger)
+			log.Info("HTTP request started",
 				"path", r.URL.Path,
 				"remote_addr", r.RemoteAddr,
 			)
 
 			next.ServeHTTP(w, r)
 
-			logger.Info("HTTP request finished",
-				"method", r.Method,
+			log.Info("HTTP request finished",
 				"path", r.URL.Path,
+				"route", chi.RouteContext(r.Context()).RoutePattern(),
 				"duration_ms", time.Since(start).Milliseconds(),
 			)
 		})
 	})
 
-	r.Route("/subscriptions", func(r chi.Router) {
-		r.Get("/total-cost", h.TotalCost)
-		r.Post("/", h.Create)
-		r.Get("/", h.GetAll)
-		r.Get("/{id}", h.GetByID)
-		r.Put("/{id}", h.Update)
-		r.Delete("/{id}", h.Delete)
+	// route limits a route to its rate limit group and API key scope and
+	// tags the request logger with the route pattern.
+	route := func(group string, scope domain.Scope) func(http.Handler) http.Handler {
+		limit, require := limiter.Group(group), requireScope(scope)
+		return func(next http.Handler) http.Handler { return logRoute(limit(require(next))) }
+	}
+	read := route("read", domain.ScopeSubscriptionsRead)
+	write := route("write", domain.ScopeSubscriptionsWrite)
+	reports := route("reports", domain.ScopeReportsRead)
+	admin := limiter.Group("admin")
+
+	r.Group(func(r chi.Router) {
+		if authn != nil {
+			r.Use(authn.Middleware)
+		}
+		r.Use(tenants.Middleware)
+
+		r.Route("/subscriptions", func(r chi.Router) {
+			r.With(reports).Get("/total-cost", h.TotalCost)
+			r.With(read).Get("/events", fh.Events)
+			r.With(write).Post("/", h.Create)
+			r.With(read).Get("/", h.GetAll)
+			r.With(read).Get("/{id}", h.GetByID)
+			r.With(write).Put("/{id}", h.Update)
+			r.With(write).Delete("/{id}", h.Delete)
+			r.With(read).Get("/{id}/history", h.History)
+			r.With(write).Post("/{id}/cancel", h.Cancel)
+			r.With(write).Post("/{id}/pause", h.Pause)
+			r.With(write).Post("/{id}/resume", h.Resume)
+			r.With(write).Post("/{id}/discounts", h.AddDiscount)
+			r.With(read).Get("/{id}/discounts", h.ListDiscounts)
+			r.With(write).Delete("/{id}/discounts/{discountID}", h.DeleteDiscount)
+		})
+		r.With(read).Get("/categories", h.Categories)
+		r.With(read).Get("/tags", h.Tags)
+		r.Route("/services", func(r chi.Router) {
+			r.With(write).Post("/", ch.Create)
+			r.With(read).Get("/", ch.GetAll)
+			r.With(read).Get("/{id}", ch.GetByID)
+			r.With(write).Put("/{id}", ch.Update)
+			r.With(write).Delete("/{id}", ch.Delete)
+		})
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(admin)
+			r.Post("/", wh.Create)
+			r.Get("/", wh.GetAll)
+			r.Get("/{id}", wh.GetByID)
+			r.Delete("/{id}", wh.Delete)
+			r.Get("/{id}/deliveries", wh.Deliveries)
+			r.Post("/{id}/deliveries/{deliveryID}/replay", wh.Replay)
+		})
+		r.Route("/admin/api-keys", func(r chi.Router) {
+			r.Use(admin)
+			r.Post("/", kh.Create)
+			r.Get("/", kh.GetAll)
+			r.Delete("/{id}", kh.Revoke)
+		})
 	})
+	r.Get("/healthz", hh.Liveness)
+	r.Get("/readyz", hh.Readiness)
+	if mh != nil {
+		r.Method(http.MethodGet, "/metrics", mh)
+	}
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
 	return r
 }
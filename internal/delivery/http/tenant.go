@@ -0,0 +1,71 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/domain"
+	"subscription-service/pkg/requestctx"
+)
+
+const defaultTenantHeader = "X-Tenant-ID"
+
+// TenantResolver decides which tenant a request acts for. Authenticated
+// requests act for the tenant of their credentials. Only with
+// authentication disabled is the tenant header used, and requests without
+// it act for the default tenant.
+type TenantResolver struct {
+	header        string
+	defaultTenant string
+	allowed       []string
+}
+
+// NewTenantResolver reads the tenant from header (X-Tenant-ID when empty).
+// A non-empty allowed list rejects every other tenant.
+func NewTenantResolver(header, defaultTenant string, allowed []string) *TenantResolver {
+	if header == "" {
+		header = defaultTenantHeader
+	}
+	if defaultTenant == "" {
+		defaultTenant = domain.DefaultTenant
+	}
+	return &TenantResolver{header: header, defaultTenant: defaultTenant, allowed: allowed}
+}
+
+// Middleware stores the tenant in the request context. It runs after
+// authentication so a token or API key bound to one tenant cannot be used
+// for another by changing the header.
+func (t *TenantResolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.Header.Get(t.header)
+		if identity := auth.FromContext(r.Context()); identity != nil {
+			if identity.Tenant == "" {
+				http.Error(w, "credentials do not name a tenant", http.StatusForbidden)
+				return
+			}
+			if tenant != "" && tenant != identity.Tenant {
+				http.Error(w, "credentials are not valid for tenant "+tenant, http.StatusForbidden)
+				return
+			}
+			tenant = identity.Tenant
+		}
+		if tenant == "" {
+			tenant = t.defaultTenant
+		}
+
+		if !domain.ValidTenant(tenant) {
+			http.Error(w, "invalid tenant id", http.StatusBadRequest)
+			return
+		}
+		if len(t.allowed) > 0 && !slices.Contains(t.allowed, tenant) {
+			http.Error(w, "unknown tenant "+tenant, http.StatusForbidden)
+			return
+		}
+
+		ctx := requestctx.WithTenant(r.Context(), tenant)
+		ctx = withLogAttrs(ctx, slog.String("tenant", tenant))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,239 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"subscription-service/internal/delivery/dto"
+	"subscription-service/internal/usecase/webhook"
+	dtoConv "subscription-service/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type WebhookHandler struct {
+	service *webhook.Service
+	logger  *slog.Logger
+}
+
+func NewWebhookHandler(service *webhook.Service, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{service: service, logger: logger}
+}
+
+// Create godoc
+// @Summary Register webhook
+// @Description Register an endpoint receiving signed JSON payloads for the given subscription event types. The secret is only returned here.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body dto.WebhookRequestDTO true "Webhook request"
+// @Success 201 {object} dto.WebhookResponseDTO
+// @Failure 400 {string} string "invalid request"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("handling webhook Create request")
+
+	var req dto.WebhookRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	endpoint := dtoConv.WebhookRequestDtoToDomain(req)
+	if err := h.service.CreateEndpoint(r.Context(), endpoint); err != nil {
+		h.logger.Error("failed to create webhook", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	h.logger.Info("webhook created successfully", slog.String("id", endpoint.ID.String()))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dtoConv.WebhookToResponseDTO(endpoint, true))
+}
+
+// GetAll godoc
+// @Summary List webhooks
+// @Description List registered webhook endpoints
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} dto.WebhookResponseDTO
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("handling webhook GetAll request")
+
+	endpoints, err := h.service.ListEndpoints(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list webhooks", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	result := make([]dto.WebhookResponseDTO, 0, len(endpoints))
+	for _, e := range endpoints {
+		result = append(result, dtoConv.WebhookToResponseDTO(e, false))
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetByID godoc
+// @Summary Get webhook
+// @Description Get a registered webhook endpoint
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} dto.WebhookResponseDTO
+// @Failure 400 {string} string "invalid id"
+// @Failure 404 {string} string "not found"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.logger.Info("handling webhook GetByID request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	endpoint, err := h.service.GetEndpoint(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to get webhook", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+	json.NewEncoder(w).Encode(dtoConv.WebhookToResponseDTO(endpoint, false))
+}
+
+// Delete godoc
+// @Summary Delete webhook
+// @Description Unregister a webhook endpoint and drop its delivery log
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {string} string "invalid id"
+// @Failure 404 {string} string "not found"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.logger.Info("handling webhook Delete request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteEndpoint(r.Context(), id); err != nil {
+		h.logger.Error("failed to delete webhook", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message": "webhook deleted successfully"}`))
+}
+
+// Deliveries godoc
+// @Summary List webhook deliveries
+// @Description List the most recent deliveries to a webhook endpoint with their status
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {array} dto.WebhookDeliveryDTO
+// @Failure 400 {string} string "invalid id"
+// @Failure 404 {string} string "not found"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	h.logger.Info("handling webhook Deliveries request", slog.String("id", idStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to list webhook deliveries", slog.String("id", idStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	result := make([]dto.WebhookDeliveryDTO, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, dtoConv.WebhookDeliveryToDTO(d))
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// Replay godoc
+// @Summary Replay webhook delivery
+// @Description Queue a past delivery to be sent again with its original payload
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param deliveryID path string true "Delivery ID"
+// @Success 202 {object} dto.WebhookDeliveryDTO
+// @Failure 400 {string} string "invalid id"
+// @Failure 404 {string} string "not found"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal error"
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries/{deliveryID}/replay [post]
+func (h *WebhookHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	deliveryIDStr := chi.URLParam(r, "deliveryID")
+	h.logger.Info("handling webhook Replay request", slog.String("id", idStr), slog.String("delivery_id", deliveryIDStr))
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("invalid UUID", slog.String("id", idStr))
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	deliveryID, err := uuid.Parse(deliveryIDStr)
+	if err != nil {
+		h.logger.Warn("invalid UUID", slog.String("delivery_id", deliveryIDStr))
+		http.Error(w, "invalid delivery id", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := h.service.Replay(r.Context(), id, deliveryID)
+	if err != nil {
+		h.logger.Error("failed to replay webhook delivery", slog.String("delivery_id", deliveryIDStr), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(dtoConv.WebhookDeliveryToDTO(delivery))
+}
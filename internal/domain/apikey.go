@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope is an operation an API key is allowed to perform.
+type Scope string
+
+const (
+	ScopeSubscriptionsRead  Scope = "subscriptions:read"
+	ScopeSubscriptionsWrite Scope = "subscriptions:write"
+	ScopeReportsRead        Scope = "reports:read"
+)
+
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeSubscriptionsRead, ScopeSubscriptionsWrite, ScopeReportsRead:
+		return true
+	}
+	return false
+}
+
+// APIKey is a credential for service-to-service clients such as batch
+// jobs. Only a hash of the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Scopes    []Scope    `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	TenantID  string     `json:"-"`
+}
+
+// Usable reports whether the key is neither revoked nor expired at now.
+func (k *APIKey) Usable(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+func (k *APIKey) HasScope(s Scope) bool {
+	return slices.Contains(k.Scopes, s)
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEntry records one change to a subscription: who made it, in which
+// request, and the subscription before and after. Before is empty for
+// creations and After for deletions.
+type AuditEntry struct {
+	ID             uuid.UUID              `json:"id"`
+	SubscriptionID uuid.UUID              `json:"subscription_id"`
+	Action         EventType              `json:"action"`
+	Actor          string                 `json:"actor,omitempty"`
+	RequestID      string                 `json:"request_id,omitempty"`
+	Before         json.RawMessage        `json:"before,omitempty"`
+	After          json.RawMessage        `json:"after,omitempty"`
+	Changes        map[string]FieldChange `json:"changes"`
+	ChangedAt      time.Time              `json:"changed_at"`
+}
+
+// FieldChange is the old and new JSON value of a changed field.
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Diff compares two JSON snapshots of a subscription field by field. Either
+// may be empty, in which case every field of the other counts as changed.
+func Diff(before, after json.RawMessage) (map[string]FieldChange, error) {
+	from, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for name, old := range from {
+		if updated, ok := to[name]; !ok || !reflect.DeepEqual(old, updated) {
+			changes[name] = FieldChange{From: old, To: to[name]}
+		}
+	}
+	for name, updated := range to {
+		if _, ok := from[name]; !ok {
+			changes[name] = FieldChange{From: nil, To: updated}
+		}
+	}
+	return changes, nil
+}
+
+func fields(snapshot json.RawMessage) (map[string]any, error) {
+	m := make(map[string]any)
+	if len(snapshot) == 0 {
+		return m, nil
+	}
+	if err := json.Unmarshal(snapshot, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
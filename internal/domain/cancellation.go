@@ -0,0 +1,63 @@
+package domain
+
+import "time"
+
+type CancelReason string
+
+const (
+	CancelTooExpensive     CancelReason = "too_expensive"
+	CancelNotUsing         CancelReason = "not_using"
+	CancelSwitchingService CancelReason = "switching_service"
+	CancelMissingFeatures  CancelReason = "missing_features"
+	CancelTechnicalIssues  CancelReason = "technical_issues"
+	CancelTemporary        CancelReason = "temporary"
+	CancelOther            CancelReason = "other"
+)
+
+var cancelReasons = map[CancelReason]bool{
+	CancelTooExpensive:     true,
+	CancelNotUsing:         true,
+	CancelSwitchingService: true,
+	CancelMissingFeatures:  true,
+	CancelTechnicalIssues:  true,
+	CancelTemporary:        true,
+	CancelOther:            true,
+}
+
+func (r CancelReason) Valid() bool {
+	return cancelReasons[r]
+}
+
+// Cancellation records why and when a subscription was cancelled. The
+// subscription itself keeps running until its EndDate.
+type Cancellation struct {
+	Reason      CancelReason `json:"reason"`
+	Comment     string       `json:"comment,omitempty"`
+	CancelledAt time.Time    `json:"cancelled_at"`
+}
+
+// AllowsEndDate reports whether an update may set the end date to end. The
+// end date of a cancelled subscription is where the cancellation put it and
+// cannot be moved or cleared; other subscriptions may change it freely.
+func (s *Subscription) AllowsEndDate(end *YearMonth) bool {
+	if s.Cancellation == nil {
+		return true
+	}
+	return end != nil && s.EndDate != nil && end.Last().Equal(s.EndDate.Last())
+}
+
+// CurrentPeriodEnd is the last date of the billing period running at now.
+// Subscriptions billed on the 1st end with the current month; others end
+// the day before their next charge date.
+func (s *Subscription) CurrentPeriodEnd(now time.Time) YearMonth {
+	if s.BillingDay == nil && !s.StartDate.HasDay {
+		return YearMonth{Time: MonthStart(now)}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	next := s.ChargeDate(today)
+	if !next.After(today) {
+		next = s.ChargeDate(MonthStart(today).AddDate(0, 1, 0))
+	}
+	return YearMonth{Time: next.AddDate(0, 0, -1), HasDay: true}
+}
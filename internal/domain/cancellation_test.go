@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestAllowsEndDate(t *testing.T) {
+	running := Subscription{StartDate: date(t, "01-2025"), EndDate: datePtr(t, "12-2025")}
+	cancelled := Subscription{StartDate: date(t, "01-2025"), EndDate: datePtr(t, "2025-06-15"), Cancellation: &Cancellation{}}
+
+	tests := []struct {
+		name string
+		sub  Subscription
+		end  *YearMonth
+		want bool
+	}{
+		{name: "running subscription moves its end", sub: running, end: datePtr(t, "03-2026"), want: true},
+		{name: "running subscription clears its end", sub: running, end: nil, want: true},
+		{name: "cancelled subscription keeps its end", sub: cancelled, end: datePtr(t, "2025-06-15"), want: true},
+		{name: "cancelled subscription moves its end", sub: cancelled, end: datePtr(t, "2025-09-15"), want: false},
+		{name: "cancelled subscription ends earlier", sub: cancelled, end: datePtr(t, "2025-06-01"), want: false},
+		{name: "cancelled subscription clears its end", sub: cancelled, end: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sub.AllowsEndDate(tt.end); got != tt.want {
+				t.Errorf("AllowsEndDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Proration selects how total cost calculations treat partial months.
+type Proration string
+
+const (
+	// ProrationNone bills the full monthly price on every billable charge
+	// date within the requested period.
+	ProrationNone Proration = "none"
+	// ProrationDaily bills each calendar month in proportion to its billable
+	// days within the requested period.
+	ProrationDaily Proration = "daily"
+)
+
+// Period is an inclusive range of days.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// NewPeriod spans from the first day covered by from to the last day
+// covered by to, so month-precision bounds include their whole months.
+func NewPeriod(from, to YearMonth) Period {
+	return Period{From: from.First(), To: to.Last()}
+}
+
+// MonthStart truncates t to the first day of its month.
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ChargeDate is the day of the given month on which the subscription is
+// billed: the billing day if set, else the day of a full start date, else
+// the first of the month. Billing days past the end of a shorter month fall
+// on its last day.
+func (s *Subscription) ChargeDate(month time.Time) time.Time {
+	day := 1
+	switch {
+	case s.BillingDay != nil:
+		day = *s.BillingDay
+	case s.StartDate.HasDay:
+		day = s.StartDate.Day()
+	}
+
+	start := MonthStart(month)
+	if last := start.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return start.AddDate(0, 0, day-1)
+}
+
+// Covers reports whether day lies within the subscription lifetime.
+func (s *Subscription) Covers(day time.Time) bool {
+	if day.Before(s.StartDate.First()) {
+		return false
+	}
+	return s.EndDate == nil || !day.After(s.EndDate.Last())
+}
+
+// Billable reports whether the subscription is charged for day: it must be
+// within the lifetime and neither in the free trial nor paused.
+func (s *Subscription) Billable(day time.Time) bool {
+	return s.Covers(day) && !s.InTrial(day) && !s.PausedOn(day)
+}
+
+// ActiveIn reports whether the subscription is billed in the given month.
+func (s *Subscription) ActiveIn(month time.Time) bool {
+	return s.Billable(s.ChargeDate(month))
+}
+
+// MonthlyCharge is the price billed in the given month after discounts.
+func (s *Subscription) MonthlyCharge(month time.Time) float64 {
+	if !s.ActiveIn(month) {
+		return 0
+	}
+	return s.discountedPrice(month)
+}
+
+// discountedPrice applies the discounts valid in month to the price.
+// Percentage discounts are applied first and compound, then fixed amounts
+// are subtracted; the result never drops below zero.
+func (s *Subscription) discountedPrice(month time.Time) float64 {
+	charge := float64(s.Price)
+	var fixed int
+	for _, d := range s.Discounts {
+		if !d.AppliesTo(month) {
+			continue
+		}
+		switch d.Kind {
+		case DiscountPercent:
+			charge *= float64(100-d.Value) / 100
+		case DiscountFixed:
+			fixed += d.Value
+		}
+	}
+	charge -= float64(fixed)
+	if charge < 0 {
+		return 0
+	}
+	return charge
+}
+
+// activeFraction is the share of the days of month that fall within period
+// and are billable.
+func (s *Subscription) activeFraction(month time.Time, period Period) float64 {
+	monthStart := MonthStart(month)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	from := latest(monthStart, period.From)
+	to := earliest(monthEnd, period.To)
+
+	var days int
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if s.Billable(day) {
+			days++
+		}
+	}
+	return float64(days) / float64(monthEnd.Day())
+}
+
+// ShareOf returns the fraction of the price attributed to userID. An owner
+// missing from the members of a shared subscription, as stored before
+// owners were included, counts with DefaultMemberWeight.
+func (s *Subscription) ShareOf(userID uuid.UUID) float64 {
+	if len(s.Members) == 0 {
+		if s.UserID == userID {
+			return 1
+		}
+		return 0
+	}
+
+	var total, own int
+	for _, m := range s.Members {
+		total += m.Weight
+		if m.UserID == userID {
+			own += m.Weight
+		}
+	}
+	if s.ownerWeight() == 0 {
+		total += DefaultMemberWeight
+		if s.UserID == userID {
+			own += DefaultMemberWeight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(own) / float64(total)
+}
+
+// Cost is what the subscription bills within period. When userID is set
+// only that user's share is counted.
+func (s *Subscription) Cost(period Period, userID *uuid.UUID, proration Proration) float64 {
+	share := 1.0
+	if userID != nil {
+		share = s.ShareOf(*userID)
+		if share == 0 {
+			return 0
+		}
+	}
+
+	var total float64
+	for month := MonthStart(period.From); !month.After(period.To); month = month.AddDate(0, 1, 0) {
+		if proration == ProrationDaily {
+			total += s.discountedPrice(month) * s.activeFraction(month, period)
+			continue
+		}
+
+		charge := s.ChargeDate(month)
+		if charge.Before(period.From) || charge.After(period.To) || !s.Billable(charge) {
+			continue
+		}
+		total += s.discountedPrice(month)
+	}
+	return total * share
+}
+
+func latest(t time.Time, others ...time.Time) time.Time {
+	for _, o := range others {
+		if o.After(t) {
+			t = o
+		}
+	}
+	return t
+}
+
+func earliest(t time.Time, others ...time.Time) time.Time {
+	for _, o := range others {
+		if o.Before(t) {
+			t = o
+		}
+	}
+	return t
+}
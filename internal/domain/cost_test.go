@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func date(t *testing.T, s string) YearMonth {
+	t.Helper()
+	ym, err := ParseYearMonth(s)
+	if err != nil {
+		t.Fatalf("ParseYearMonth(%q): %v", s, err)
+	}
+	return ym
+}
+
+func datePtr(t *testing.T, s string) *YearMonth {
+	ym := date(t, s)
+	return &ym
+}
+
+func TestSubscriptionCost(t *testing.T) {
+	owner := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	member := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	billingDay := 31
+
+	tests := []struct {
+		name      string
+		sub       Subscription
+		from, to  string
+		userID    *uuid.UUID
+		proration Proration
+		want      float64
+	}{
+		{
+			name: "every month of the range",
+			sub:  Subscription{Price: 100, StartDate: date(t, "01-2025")},
+			from: "01-2025", to: "03-2025",
+			want: 300,
+		},
+		{
+			name: "started before the range",
+			sub:  Subscription{Price: 100, StartDate: date(t, "06-2024")},
+			from: "01-2025", to: "02-2025",
+			want: 200,
+		},
+		{
+			name: "ends within the range",
+			sub:  Subscription{Price: 100, StartDate: date(t, "01-2025"), EndDate: datePtr(t, "02-2025")},
+			from: "01-2025", to: "04-2025",
+			want: 200,
+		},
+		{
+			name: "trial months are free",
+			sub:  Subscription{Price: 100, StartDate: date(t, "01-2025"), TrialEnd: datePtr(t, "01-2025")},
+			from: "01-2025", to: "03-2025",
+			want: 200,
+		},
+		{
+			name: "paused month is skipped",
+			sub: Subscription{Price: 100, StartDate: date(t, "01-2025"), Pauses: []Pause{
+				{StartDate: date(t, "02-2025"), EndDate: datePtr(t, "02-2025")},
+			}},
+			from: "01-2025", to: "03-2025",
+			want: 200,
+		},
+		{
+			name: "charge date past the end of the range",
+			sub:  Subscription{Price: 100, StartDate: date(t, "2025-01-16")},
+			from: "2025-01-01", to: "2025-01-15",
+			want: 0,
+		},
+		{
+			name: "billing day clamped to a short month",
+			sub:  Subscription{Price: 100, StartDate: date(t, "01-2025"), BillingDay: &billingDay},
+			from: "2025-02-01", to: "2025-02-28",
+			want: 100,
+		},
+		{
+			name: "daily proration of a mid-month start",
+			sub:  Subscription{Price: 310, StartDate: date(t, "2025-01-16")},
+			from: "01-2025", to: "01-2025",
+			proration: ProrationDaily,
+			want:      160,
+		},
+		{
+			name: "daily proration of a partial range",
+			sub:  Subscription{Price: 280, StartDate: date(t, "01-2025")},
+			from: "2025-02-01", to: "2025-02-07",
+			proration: ProrationDaily,
+			want:      70,
+		},
+		{
+			name: "daily proration skips paused days",
+			sub: Subscription{Price: 280, StartDate: date(t, "01-2025"), Pauses: []Pause{
+				{StartDate: date(t, "2025-02-01"), EndDate: datePtr(t, "2025-02-14")},
+			}},
+			from: "02-2025", to: "02-2025",
+			proration: ProrationDaily,
+			want:      140,
+		},
+		{
+			name: "member share with the owner listed",
+			sub: Subscription{Price: 100, UserID: owner, StartDate: date(t, "01-2025"), Members: []Member{
+				{UserID: owner, Weight: 1}, {UserID: member, Weight: 3},
+			}},
+			from: "01-2025", to: "01-2025",
+			userID: &member,
+			want:   75,
+		},
+		{
+			name: "unlisted owner still takes a share",
+			sub: Subscription{Price: 100, UserID: owner, StartDate: date(t, "01-2025"), Members: []Member{
+				{UserID: member, Weight: 1},
+			}},
+			from: "01-2025", to: "01-2025",
+			userID: &owner,
+			want:   50,
+		},
+		{
+			name: "user without a share",
+			sub:  Subscription{Price: 100, UserID: owner, StartDate: date(t, "01-2025")},
+			from: "01-2025", to: "01-2025",
+			userID: &member,
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proration := tt.proration
+			if proration == "" {
+				proration = ProrationNone
+			}
+			got := tt.sub.Cost(NewPeriod(date(t, tt.from), date(t, tt.to)), tt.userID, proration)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChargeDate(t *testing.T) {
+	day := func(d int) *int { return &d }
+	tests := []struct {
+		name  string
+		sub   Subscription
+		month string
+		want  string
+	}{
+		{name: "month precision start", sub: Subscription{StartDate: date(t, "01-2025")}, month: "03-2025", want: "2025-03-01"},
+		{name: "day of the start date", sub: Subscription{StartDate: date(t, "2025-01-16")}, month: "03-2025", want: "2025-03-16"},
+		{name: "billing day wins", sub: Subscription{StartDate: date(t, "2025-01-16"), BillingDay: day(5)}, month: "03-2025", want: "2025-03-05"},
+		{name: "clamped to February", sub: Subscription{StartDate: date(t, "2025-01-31")}, month: "02-2025", want: "2025-02-28"},
+		{name: "clamped in a leap year", sub: Subscription{StartDate: date(t, "01-2024"), BillingDay: day(30)}, month: "02-2024", want: "2024-02-29"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.sub.ChargeDate(date(t, tt.month).Time).Format(time.DateOnly)
+			if got != tt.want {
+				t.Errorf("ChargeDate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DiscountKind string
+
+const (
+	DiscountPercent DiscountKind = "percent"
+	DiscountFixed   DiscountKind = "fixed"
+)
+
+// Discount lowers the monthly price of a subscription for the months from
+// StartDate to EndDate inclusive. An open EndDate means the discount never
+// expires. Value is a percentage for DiscountPercent and an amount in price
+// units for DiscountFixed.
+type Discount struct {
+	ID             uuid.UUID    `json:"id"`
+	SubscriptionID uuid.UUID    `json:"subscription_id"`
+	Kind           DiscountKind `json:"kind"`
+	Value          int          `json:"value"`
+	StartDate      YearMonth    `json:"start_date"`
+	EndDate        *YearMonth   `json:"end_date,omitempty"`
+	Description    string       `json:"description,omitempty"`
+}
+
+// AppliesTo reports whether the discount is valid in the given month.
+func (d Discount) AppliesTo(month time.Time) bool {
+	month = MonthStart(month)
+	if month.Before(MonthStart(d.StartDate.Time)) {
+		return false
+	}
+	return d.EndDate == nil || !month.After(MonthStart(d.EndDate.Time))
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMonthlyChargeDiscounts(t *testing.T) {
+	tests := []struct {
+		name      string
+		price     int
+		discounts []Discount
+		month     string
+		want      float64
+	}{
+		{
+			name:  "no discount",
+			price: 100, month: "01-2025",
+			want: 100,
+		},
+		{
+			name:  "percentage",
+			price: 100, month: "01-2025",
+			discounts: []Discount{{Kind: DiscountPercent, Value: 10, StartDate: date(t, "01-2025")}},
+			want:      90,
+		},
+		{
+			name:  "percentages compound before fixed amounts",
+			price: 100, month: "02-2025",
+			discounts: []Discount{
+				{Kind: DiscountFixed, Value: 5, StartDate: date(t, "02-2025")},
+				{Kind: DiscountPercent, Value: 10, StartDate: date(t, "01-2025")},
+				{Kind: DiscountPercent, Value: 50, StartDate: date(t, "02-2025")},
+			},
+			want: 40,
+		},
+		{
+			name:  "expired discount",
+			price: 100, month: "03-2025",
+			discounts: []Discount{{Kind: DiscountPercent, Value: 10, StartDate: date(t, "01-2025"), EndDate: datePtr(t, "02-2025")}},
+			want:      100,
+		},
+		{
+			name:  "discount not yet started",
+			price: 100, month: "01-2025",
+			discounts: []Discount{{Kind: DiscountFixed, Value: 30, StartDate: date(t, "02-2025")}},
+			want:      100,
+		},
+		{
+			name:  "never below zero",
+			price: 10, month: "01-2025",
+			discounts: []Discount{{Kind: DiscountFixed, Value: 20, StartDate: date(t, "01-2025")}},
+			want:      0,
+		},
+		{
+			name:  "not billed before the start",
+			price: 100, month: "12-2024",
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := Subscription{Price: tt.price, StartDate: date(t, "01-2025"), Discounts: tt.discounts}
+			got := sub.MonthlyCharge(date(t, tt.month).Time)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("MonthlyCharge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
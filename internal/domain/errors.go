@@ -0,0 +1,22 @@
+package domain
+
+import "errors"
+
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrUnauthorized   = errors.New("authentication required")
+	ErrForbidden      = errors.New("forbidden")
+	ErrUnknownService = errors.New("service_id does not match any catalog service")
+	ErrPriceRequired  = errors.New("price is required when the service has no default price")
+	ErrDuplicateAlias = errors.New("alias is already used by another service")
+	ErrDuplicateName  = errors.New("service with this name already exists")
+
+	ErrAlreadyCancelled  = errors.New("subscription is already cancelled")
+	ErrCancelBeforeStart = errors.New("cancellation cannot take effect before start_date")
+	ErrCancelAfterEnd    = errors.New("cancellation cannot take effect after end_date")
+	ErrCancelledEndDate  = errors.New("end_date of a cancelled subscription cannot be changed")
+	ErrAlreadyPaused     = errors.New("subscription is already paused")
+	ErrPauseEndsEarly    = errors.New("pause end_date cannot be before its start_date")
+	ErrNotPaused         = errors.New("subscription is not paused")
+	ErrNotRunning        = errors.New("subscription has ended")
+)
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventSubscriptionCreated   EventType = "subscription.created"
+	EventSubscriptionUpdated   EventType = "subscription.updated"
+	EventSubscriptionDeleted   EventType = "subscription.deleted"
+	EventSubscriptionCancelled EventType = "subscription.cancelled"
+)
+
+var eventTypes = map[EventType]bool{
+	EventSubscriptionCreated:   true,
+	EventSubscriptionUpdated:   true,
+	EventSubscriptionDeleted:   true,
+	EventSubscriptionCancelled: true,
+}
+
+func (t EventType) Valid() bool {
+	return eventTypes[t]
+}
+
+// Event is a domain event about a subscription. Data carries the
+// subscription as it was after the change, or right before it was deleted.
+// Sequence is the position in the event log, assigned once published.
+type Event struct {
+	ID             uuid.UUID     `json:"id"`
+	Sequence       int64         `json:"sequence,omitempty"`
+	TenantID       string        `json:"tenant_id,omitempty"`
+	Type           EventType     `json:"type"`
+	SubscriptionID uuid.UUID     `json:"subscription_id"`
+	UserID         uuid.UUID     `json:"user_id"`
+	OccurredAt     time.Time     `json:"occurred_at"`
+	Data           *Subscription `json:"data,omitempty"`
+}
+
+func NewEvent(eventType EventType, sub *Subscription) Event {
+	return Event{
+		ID:             uuid.New(),
+		Type:           eventType,
+		SubscriptionID: sub.ID,
+		UserID:         sub.UserID,
+		OccurredAt:     time.Now().UTC(),
+		Data:           sub,
+	}
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "strings"
+
+// NormalizeLabel is the canonical form of category and tag names.
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// NormalizeLabels normalizes labels, dropping empty values and duplicates.
+func NormalizeLabels(labels []string) []string {
+	seen := make(map[string]bool, len(labels))
+	out := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = NormalizeLabel(label)
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		out = append(out, label)
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationKind is the subscription event a reminder announces.
+type NotificationKind string
+
+const (
+	NotificationRenewal     NotificationKind = "renewal"
+	NotificationEnding      NotificationKind = "ending"
+	NotificationTrialEnding NotificationKind = "trial_ending"
+)
+
+// NotificationStatus tracks delivery. Failed notifications are retried
+// until they run out of attempts and become dead.
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed"
+	NotificationDead    NotificationStatus = "dead"
+)
+
+// Notification is a reminder queued for delivery to the subscription owner.
+// DueDate is the day the announced event happens, so a subscription gets at
+// most one reminder of each kind per event.
+type Notification struct {
+	ID             uuid.UUID          `json:"id"`
+	SubscriptionID uuid.UUID          `json:"subscription_id"`
+	UserID         uuid.UUID          `json:"user_id"`
+	Kind           NotificationKind   `json:"kind"`
+	DueDate        time.Time          `json:"due_date"`
+	Status         NotificationStatus `json:"status"`
+	Attempts       int                `json:"attempts"`
+	LastError      string             `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time          `json:"next_attempt_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	SentAt         *time.Time         `json:"sent_at,omitempty"`
+	ServiceName    string             `json:"service_name,omitempty"`
+	Price          int                `json:"price,omitempty"`
+}
+
+// Subject is a one-line human readable summary of the reminder.
+func (n Notification) Subject() string {
+	date := n.DueDate.Format("2006-01-02")
+	switch n.Kind {
+	case NotificationRenewal:
+		return fmt.Sprintf("%s renews on %s", n.ServiceName, date)
+	case NotificationTrialEnding:
+		return fmt.Sprintf("%s trial ends on %s", n.ServiceName, date)
+	case NotificationEnding:
+		return fmt.Sprintf("%s ends on %s", n.ServiceName, date)
+	default:
+		return fmt.Sprintf("%s: %s on %s", n.ServiceName, n.Kind, date)
+	}
+}
+
+// Reminders lists the renewals, trial ends and subscription ends falling
+// between from and to inclusive. The first charge on the start date is not
+// a renewal and is never announced.
+func (s *Subscription) Reminders(from, to time.Time) []Notification {
+	var reminders []Notification
+	add := func(kind NotificationKind, due time.Time) {
+		reminders = append(reminders, Notification{
+			SubscriptionID: s.ID,
+			UserID:         s.UserID,
+			Kind:           kind,
+			DueDate:        due,
+			Status:         NotificationPending,
+		})
+	}
+	within := func(day time.Time) bool {
+		return !day.Before(from) && !day.After(to)
+	}
+
+	if s.TrialEnd != nil && within(s.TrialEnd.Last()) && s.Covers(s.TrialEnd.Last()) {
+		add(NotificationTrialEnding, s.TrialEnd.Last())
+	}
+
+	for month := MonthStart(from); !month.After(to); month = month.AddDate(0, 1, 0) {
+		charge := s.ChargeDate(month)
+		if within(charge) && charge.After(s.StartDate.First()) && s.Billable(charge) {
+			add(NotificationRenewal, charge)
+		}
+	}
+
+	if s.EndDate != nil && within(s.EndDate.Last()) {
+		add(NotificationEnding, s.EndDate.Last())
+	}
+	return reminders
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "github.com/google/uuid"
+
+type Service struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Aliases      []string  `json:"aliases"`
+	Category     string    `json:"category,omitempty"`
+	DefaultPrice *int      `json:"default_price,omitempty"`
+}
+
+// NormalizeServiceName folds case and whitespace so that "Netflix",
+// " netflix " and "NETFLIX" compare equal.
+func NormalizeServiceName(name string) string {
+	return NormalizeLabel(name)
+}
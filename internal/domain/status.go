@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusUpcoming         Status = "upcoming"
+	StatusTrial            Status = "trial"
+	StatusActive           Status = "active"
+	StatusPaused           Status = "paused"
+	StatusCancelledPending Status = "cancelled-pending"
+	StatusEnded            Status = "ended"
+)
+
+var statuses = map[Status]bool{
+	StatusUpcoming:         true,
+	StatusTrial:            true,
+	StatusActive:           true,
+	StatusPaused:           true,
+	StatusCancelledPending: true,
+	StatusEnded:            true,
+}
+
+func (s Status) Valid() bool {
+	return statuses[s]
+}
+
+// Pause suspends billing from StartDate to EndDate inclusive. An open
+// EndDate means the subscription stays paused until resumed.
+type Pause struct {
+	ID        uuid.UUID  `json:"id"`
+	StartDate YearMonth  `json:"start_date"`
+	EndDate   *YearMonth `json:"end_date,omitempty"`
+}
+
+func (p Pause) Covers(day time.Time) bool {
+	if day.Before(p.StartDate.First()) {
+		return false
+	}
+	return p.EndDate == nil || !day.After(p.EndDate.Last())
+}
+
+// Overlaps reports whether the two pauses share at least one day.
+func (p Pause) Overlaps(other Pause) bool {
+	if p.EndDate != nil && p.EndDate.Last().Before(other.StartDate.First()) {
+		return false
+	}
+	if other.EndDate != nil && other.EndDate.Last().Before(p.StartDate.First()) {
+		return false
+	}
+	return true
+}
+
+// InTrial reports whether day falls within the free trial.
+func (s *Subscription) InTrial(day time.Time) bool {
+	return s.TrialEnd != nil && !day.Before(s.StartDate.First()) && !day.After(s.TrialEnd.Last())
+}
+
+// PausedOn reports whether billing is paused on day.
+func (s *Subscription) PausedOn(day time.Time) bool {
+	for _, p := range s.Pauses {
+		if p.Covers(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// PauseOverlaps reports whether p shares a day with any existing pause.
+func (s *Subscription) PauseOverlaps(p Pause) bool {
+	for _, existing := range s.Pauses {
+		if existing.Overlaps(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenPause returns the pause without an end date, if any.
+func (s *Subscription) OpenPause() *Pause {
+	for i := range s.Pauses {
+		if s.Pauses[i].EndDate == nil {
+			return &s.Pauses[i]
+		}
+	}
+	return nil
+}
+
+// StatusAt derives the lifecycle status on the given day. A subscription
+// that has been cancelled but is still running until its end date is
+// cancelled-pending; one with a fixed end date that was never cancelled
+// stays active until it ends.
+func (s *Subscription) StatusAt(day time.Time) Status {
+	switch {
+	case day.Before(s.StartDate.First()):
+		return StatusUpcoming
+	case s.EndDate != nil && day.After(s.EndDate.Last()):
+		return StatusEnded
+	case s.PausedOn(day):
+		return StatusPaused
+	case s.InTrial(day):
+		return StatusTrial
+	case s.Cancellation != nil:
+		return StatusCancelledPending
+	default:
+		return StatusActive
+	}
+}
+
+// Today is the current UTC date with day precision.
+func Today() YearMonth {
+	now := time.Now().UTC()
+	return YearMonth{Time: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), HasDay: true}
+}
@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseOverlaps(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Pause
+		want bool
+	}{
+		{
+			name: "disjoint months",
+			a:    Pause{StartDate: date(t, "01-2025"), EndDate: datePtr(t, "02-2025")},
+			b:    Pause{StartDate: date(t, "03-2025"), EndDate: datePtr(t, "04-2025")},
+			want: false,
+		},
+		{
+			name: "month end covers a day start in the same month",
+			a:    Pause{StartDate: date(t, "01-2025"), EndDate: datePtr(t, "02-2025")},
+			b:    Pause{StartDate: date(t, "2025-02-20")},
+			want: true,
+		},
+		{
+			name: "back to back days",
+			a:    Pause{StartDate: date(t, "2025-02-01"), EndDate: datePtr(t, "2025-02-14")},
+			b:    Pause{StartDate: date(t, "2025-02-15"), EndDate: datePtr(t, "2025-02-20")},
+			want: false,
+		},
+		{
+			name: "sharing the last day",
+			a:    Pause{StartDate: date(t, "2025-02-01"), EndDate: datePtr(t, "2025-02-14")},
+			b:    Pause{StartDate: date(t, "2025-02-14"), EndDate: datePtr(t, "2025-02-20")},
+			want: true,
+		},
+		{
+			name: "open pause reaches everything after it",
+			a:    Pause{StartDate: date(t, "01-2025")},
+			b:    Pause{StartDate: date(t, "06-2030"), EndDate: datePtr(t, "07-2030")},
+			want: true,
+		},
+		{
+			name: "open pause starting after a closed one",
+			a:    Pause{StartDate: date(t, "2025-03-01")},
+			b:    Pause{StartDate: date(t, "01-2025"), EndDate: datePtr(t, "02-2025")},
+			want: false,
+		},
+		{
+			name: "one inside the other",
+			a:    Pause{StartDate: date(t, "01-2025"), EndDate: datePtr(t, "06-2025")},
+			b:    Pause{StartDate: date(t, "2025-03-10"), EndDate: datePtr(t, "2025-03-12")},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Overlaps(tt.b); got != tt.want {
+				t.Errorf("a.Overlaps(b) = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.Overlaps(tt.a); got != tt.want {
+				t.Errorf("b.Overlaps(a) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPausedOn(t *testing.T) {
+	sub := Subscription{StartDate: date(t, "01-2025"), Pauses: []Pause{
+		// Month precision: the whole of March.
+		{StartDate: date(t, "03-2025"), EndDate: datePtr(t, "03-2025")},
+		// Day precision within a month.
+		{StartDate: date(t, "2025-05-10"), EndDate: datePtr(t, "2025-05-20")},
+		// Day start with a month-precision end in the same month.
+		{StartDate: date(t, "2025-07-15"), EndDate: datePtr(t, "07-2025")},
+		{StartDate: date(t, "2025-10-01")},
+	}}
+
+	tests := []struct {
+		day  string
+		want bool
+	}{
+		{"2025-02-28", false},
+		{"2025-03-01", true},
+		{"2025-03-31", true},
+		{"2025-04-01", false},
+		{"2025-05-09", false},
+		{"2025-05-10", true},
+		{"2025-05-20", true},
+		{"2025-05-21", false},
+		{"2025-07-14", false},
+		{"2025-07-31", true},
+		{"2025-08-01", false},
+		{"2025-09-30", false},
+		{"2031-01-01", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.day, func(t *testing.T) {
+			if got := sub.PausedOn(date(t, tt.day).Time); got != tt.want {
+				t.Errorf("PausedOn(%s) = %v, want %v", tt.day, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusAt(t *testing.T) {
+	sub := Subscription{
+		StartDate: date(t, "01-2025"),
+		TrialEnd:  datePtr(t, "01-2025"),
+		EndDate:   datePtr(t, "12-2025"),
+		Pauses:    []Pause{{StartDate: date(t, "2025-05-10"), EndDate: datePtr(t, "2025-05-20")}},
+	}
+	cancelled := sub
+	cancelled.Cancellation = &Cancellation{}
+
+	tests := []struct {
+		name string
+		sub  Subscription
+		day  string
+		want Status
+	}{
+		{"before the start", sub, "2024-12-31", StatusUpcoming},
+		{"in the trial", sub, "2025-01-31", StatusTrial},
+		{"after the trial", sub, "2025-02-01", StatusActive},
+		{"paused", sub, "2025-05-15", StatusPaused},
+		{"resumed", sub, "2025-05-21", StatusActive},
+		{"cancelled but running", cancelled, "2025-06-01", StatusCancelledPending},
+		{"paused wins over cancelled", cancelled, "2025-05-15", StatusPaused},
+		{"last day", sub, "2025-12-31", StatusActive},
+		{"ended", cancelled, "2026-01-01", StatusEnded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day, err := time.Parse(time.DateOnly, tt.day)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.sub.StatusAt(day); got != tt.want {
+				t.Errorf("StatusAt(%s) = %s, want %s", tt.day, got, tt.want)
+			}
+		})
+	}
+}
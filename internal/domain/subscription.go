@@ -8,29 +8,157 @@ import (
 )
 
 type Subscription struct {
-	ID          uuid.UUID   `json:"id"`
-	ServiceName string      `json:"service_name"`
-	Price       int         `json:"price"`
-	UserID      uuid.UUID   `json:"user_id"`
-	StartDate   YearMonth   `json:"start_date"`
-	EndDate     *YearMonth  `json:"end_date,omitempty"`
+	ID           uuid.UUID     `json:"id"`
+	ServiceName  string        `json:"service_name"`
+	ServiceID    *uuid.UUID    `json:"service_id,omitempty"`
+	Price        int           `json:"price"`
+	UserID       uuid.UUID     `json:"user_id"`
+	StartDate    YearMonth     `json:"start_date"`
+	EndDate      *YearMonth    `json:"end_date,omitempty"`
+	BillingDay   *int          `json:"billing_day,omitempty"`
+	TrialEnd     *YearMonth    `json:"trial_end_date,omitempty"`
+	Pauses       []Pause       `json:"pauses,omitempty"`
+	Categories   []string      `json:"categories,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
+	Members      []Member      `json:"members,omitempty"`
+	Discounts    []Discount    `json:"discounts,omitempty"`
+	Cancellation *Cancellation `json:"cancellation,omitempty"`
 }
 
+// Member is a user sharing a subscription. When a subscription has members
+// its price is split between them in proportion to Weight; otherwise the
+// owner (UserID) carries the whole price. The owner always takes part in
+// the split, with DefaultMemberWeight unless listed with another weight.
+type Member struct {
+	UserID uuid.UUID `json:"user_id"`
+	Weight int       `json:"weight"`
+}
+
+// DefaultMemberWeight is the weight of a member listed without one, and of
+// the owner when not listed at all.
+const DefaultMemberWeight = 1
+
+// IncludeOwner adds the owner to the members of a shared subscription with
+// DefaultMemberWeight when it is not listed, so the payer is never left
+// with a share of zero.
+func (s *Subscription) IncludeOwner() {
+	if len(s.Members) == 0 || s.ownerWeight() != 0 {
+		return
+	}
+	s.Members = append(s.Members, Member{UserID: s.UserID, Weight: DefaultMemberWeight})
+}
+
+// ownerWeight is the weight the owner is listed with among the members, or
+// zero when not listed.
+func (s *Subscription) ownerWeight() int {
+	for _, m := range s.Members {
+		if m.UserID == s.UserID {
+			return m.Weight
+		}
+	}
+	return 0
+}
+
+// Involves reports whether userID owns or shares the subscription.
+func (s *Subscription) Involves(userID uuid.UUID) bool {
+	if s.UserID == userID {
+		return true
+	}
+	for _, m := range s.Members {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscriptionFilter narrows listings and cost calculations. Nil and empty
+// fields do not filter. UserID matches both owners and members, and makes
+// cost calculations count only that user's share. A subscription matches
+// Categories if it has any of them and matches Tags only if it carries all
+// of them. Status is derived rather than stored, so it is evaluated against
+// AsOf by the service layer after loading. With RecordedAt set,
+// subscriptions are read as they were recorded at that moment.
+type SubscriptionFilter struct {
+	UserID      *uuid.UUID
+	ServiceName *string
+	Categories  []string
+	Tags        []string
+	Status      Status
+	AsOf        YearMonth
+	RecordedAt  *time.Time
+}
+
+type CostGroupBy string
+
+const (
+	GroupByCategory CostGroupBy = "category"
+	GroupByTag      CostGroupBy = "tag"
+	GroupByService  CostGroupBy = "service"
+)
+
+type CostGroup struct {
+	Key   string `json:"key"`
+	Total int64  `json:"total"`
+}
+
+const (
+	monthLayout = "01-2006"
+	dayLayout   = "2006-01-02"
+)
+
+// YearMonth is a subscription date. It is usually given with month precision
+// (MM-YYYY) and then points at the first day of the month; HasDay is set when
+// it was given as a full date (YYYY-MM-DD).
 type YearMonth struct {
 	time.Time
+	HasDay bool
+}
+
+// ParseYearMonth accepts both MM-YYYY and YYYY-MM-DD.
+func ParseYearMonth(s string) (YearMonth, error) {
+	if t, err := time.Parse(monthLayout, s); err == nil {
+		return YearMonth{Time: t}, nil
+	}
+	t, err := time.Parse(dayLayout, s)
+	if err != nil {
+		return YearMonth{}, err
+	}
+	return YearMonth{Time: t, HasDay: true}, nil
+}
+
+// String formats the date in the precision it was given with.
+func (ym YearMonth) String() string {
+	if ym.HasDay {
+		return ym.Time.Format(dayLayout)
+	}
+	return ym.Time.Format(monthLayout)
+}
+
+// First is the first day covered by the date.
+func (ym YearMonth) First() time.Time {
+	return ym.Time
+}
+
+// Last is the last day covered by the date: the day itself for full dates
+// and the last day of the month otherwise.
+func (ym YearMonth) Last() time.Time {
+	if ym.HasDay {
+		return ym.Time
+	}
+	return MonthStart(ym.Time).AddDate(0, 1, -1)
 }
 
 func (ym *YearMonth) UnmarshalJSON(b []byte) error {
 	s := strings.Trim(string(b), `"`)
-	t, err := time.Parse("01-2006", s)
+	parsed, err := ParseYearMonth(s)
 	if err != nil {
 		return err
 	}
-	ym.Time = t
+	*ym = parsed
 	return nil
 }
 
 func (ym YearMonth) MarshalJSON() ([]byte, error) {
-	return []byte(`"` + ym.Time.Format("01-2006") + `"`), nil
+	return []byte(`"` + ym.String() + `"`), nil
 }
-
@@ -0,0 +1,15 @@
+package domain
+
+import "regexp"
+
+// DefaultTenant owns the data that predates multi-tenancy and serves
+// requests that do not name a tenant.
+const DefaultTenant = "default"
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidTenant reports whether id is a well-formed tenant ID: lowercase
+// letters, digits, dashes and underscores, at most 63 characters.
+func ValidTenant(id string) bool {
+	return tenantPattern.MatchString(id)
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"subscription-service/internal/domain"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// KafkaPublisher produces events to a single topic keyed by subscription
+// ID, so all events about one subscription land in the same partition and
+// keep their order.
+type KafkaPublisher struct {
+	client *kgo.Client
+	logger *slog.Logger
+}
+
+// NewKafkaPublisher pings the brokers before returning, because the client
+// only connects on first use and would otherwise hide a wrong address until
+// the first event.
+func NewKafkaPublisher(ctx context.Context, brokers []string, topic string, logger *slog.Logger) (*KafkaPublisher, error) {
+	if topic == "" {
+		topic = "subscription-events"
+	}
+
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(brokers...),
+		kgo.DefaultProduceTopic(topic),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	logger.Info("kafka publisher connected", "brokers", brokers, "topic", topic)
+	return &KafkaPublisher{client: client, logger: logger}, nil
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event domain.Event) error {
+	data, err := encodeMessage(event)
+	if err != nil {
+		return err
+	}
+
+	record := &kgo.Record{
+		Key:   []byte(event.SubscriptionID.String()),
+		Value: data,
+		Headers: []kgo.RecordHeader{
+			{Key: "schema", Value: []byte(SchemaVersion)},
+			{Key: "event_type", Value: []byte(event.Type)},
+			{Key: "event_id", Value: []byte(event.ID.String())},
+		},
+	}
+	if err := p.client.ProduceSync(ctx, record).FirstErr(); err != nil {
+		p.logger.Error("kafka produce failed", "event_id", event.ID.String(), "error", err)
+		return err
+	}
+	return nil
+}
+
+// Close flushes buffered records and closes the client.
+func (p *KafkaPublisher) Close() error {
+	p.client.Close()
+	return nil
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"subscription-service/internal/domain"
+)
+
+// LogPublisher writes domain events to the log. It is the default sink when
+// no other event transport is configured.
+type LogPublisher struct {
+	logger *slog.Logger
+}
+
+func NewLogPublisher(logger *slog.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event domain.Event) error {
+	p.logger.Info("domain event",
+		"event_id", event.ID.String(),
+		"event_type", string(event.Type),
+		"subscription_id", event.SubscriptionID.String(),
+		"user_id", event.UserID.String(),
+	)
+	return nil
+}
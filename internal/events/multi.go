@@ -0,0 +1,27 @@
+package events
+
+import (
+	"context"
+	"errors"
+
+	"subscription-service/internal/domain"
+)
+
+// Publisher is a sink for domain events.
+type Publisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+// Multi hands every event to each of its publishers in turn. A failing
+// publisher does not stop the others; their errors are joined.
+type Multi []Publisher
+
+func (m Multi) Publish(ctx context.Context, event domain.Event) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
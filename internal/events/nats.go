@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"subscription-service/internal/domain"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSPublisher publishes events to a JetStream stream on the subject
+// "<prefix>.<event type>", e.g. subscriptions.subscription.created. The
+// event ID is used as the message ID so JetStream drops the duplicates the
+// outbox relay may produce.
+type NATSPublisher struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	prefix string
+	logger *slog.Logger
+}
+
+// NewNATSPublisher connects to url and creates or updates the stream so
+// that it captures every subject under prefix.
+func NewNATSPublisher(ctx context.Context, url, stream, prefix string, logger *slog.Logger) (*NATSPublisher, error) {
+	if stream == "" {
+		stream = "SUBSCRIPTIONS"
+	}
+	if prefix == "" {
+		prefix = "subscriptions"
+	}
+
+	conn, err := nats.Connect(url, nats.Name("subscription-service"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     stream,
+		Subjects: []string{prefix + ".>"},
+	}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	logger.Info("nats publisher connected", "url", url, "stream", stream, "prefix", prefix)
+	return &NATSPublisher{conn: conn, js: js, prefix: prefix, logger: logger}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event domain.Event) error {
+	data, err := encodeMessage(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(p.prefix + "." + string(event.Type))
+	msg.Data = data
+	msg.Header.Set("Schema", SchemaVersion)
+	msg.Header.Set(jetstream.MsgIDHeader, event.ID.String())
+
+	if _, err := p.js.PublishMsg(ctx, msg); err != nil {
+		p.logger.Error("nats publish failed", "event_id", event.ID.String(), "error", err)
+		return err
+	}
+	return nil
+}
+
+// Close flushes pending messages and closes the connection.
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
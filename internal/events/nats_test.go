@@ -0,0 +1,109 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func runNATSServer(t *testing.T) *server.Server {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("start nats server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func TestNATSPublisher(t *testing.T) {
+	srv := runNATSServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	p, err := NewNATSPublisher(ctx, srv.ClientURL(), "TEST", "subs", logger)
+	if err != nil {
+		t.Fatalf("NewNATSPublisher: %v", err)
+	}
+	defer p.Close()
+
+	event := domain.Event{
+		ID:             uuid.New(),
+		TenantID:       "acme",
+		Type:           domain.EventSubscriptionCreated,
+		SubscriptionID: uuid.New(),
+		UserID:         uuid.New(),
+		OccurredAt:     time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC),
+	}
+	// The relay may publish the same event twice; JetStream must keep one.
+	for range 2 {
+		if err := p.Publish(ctx, event); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	stream, err := p.js.Stream(ctx, "TEST")
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.State.Msgs != 1 {
+		t.Errorf("stream holds %d messages, want 1", info.State.Msgs)
+	}
+
+	raw, err := stream.GetLastMsgForSubject(ctx, "subs.subscription.created")
+	if err != nil {
+		t.Fatalf("GetLastMsgForSubject: %v", err)
+	}
+	if got := raw.Header.Get("Schema"); got != SchemaVersion {
+		t.Errorf("Schema header = %q, want %q", got, SchemaVersion)
+	}
+	if got := raw.Header.Get(jetstream.MsgIDHeader); got != event.ID.String() {
+		t.Errorf("message id = %q, want %q", got, event.ID)
+	}
+	var msg Message
+	if err := json.Unmarshal(raw.Data, &msg); err != nil {
+		t.Fatalf("decode message: %v", err)
+	}
+	if msg.ID != event.ID.String() || msg.TenantID != "acme" || msg.Type != string(event.Type) {
+		t.Errorf("message = %+v, want event %s of tenant acme", msg, event.ID)
+	}
+}
+
+func TestNATSPublisherUnreachable(t *testing.T) {
+	srv := runNATSServer(t)
+	url := srv.ClientURL()
+	srv.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if p, err := NewNATSPublisher(ctx, url, "", "", logger); err == nil {
+		p.Close()
+		t.Fatal("NewNATSPublisher succeeded without a server")
+	}
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"subscription-service/internal/domain"
+	"subscription-service/internal/health"
+)
+
+// Outbox is the durable queue of events written alongside each change.
+type Outbox interface {
+	Process(ctx context.Context, limit, maxAttempts int, publish func(domain.Event) error) (int, error)
+}
+
+// Relay moves events from the outbox to a publisher. Delivery is at least
+// once: a crash between publishing and committing republishes the event,
+// so sinks should deduplicate on the event ID. An event the publisher
+// rejects maxAttempts times is parked as dead.
+type Relay struct {
+	outbox      Outbox
+	publisher   Publisher
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	logger      *slog.Logger
+	heartbeat   *health.Heartbeat
+}
+
+func NewRelay(outbox Outbox, publisher Publisher, interval time.Duration, batchSize, maxAttempts int, logger *slog.Logger) *Relay {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+	return &Relay{outbox: outbox, publisher: publisher, interval: interval, batchSize: batchSize, maxAttempts: maxAttempts, logger: logger}
+}
+
+// SetHeartbeat reports the progress of Run to h, for readiness checks.
+func (r *Relay) SetHeartbeat(h *health.Heartbeat) {
+	r.heartbeat = h
+}
+
+// Run polls the outbox until ctx is cancelled. Full batches are followed
+// immediately by the next one so a backlog drains without waiting.
+func (r *Relay) Run(ctx context.Context) {
+	r.logger.Info("outbox: relay started", "interval", r.interval.String())
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := r.outbox.Process(ctx, r.batchSize, r.maxAttempts, func(event domain.Event) error {
+			return r.publisher.Publish(ctx, event)
+		})
+		if err != nil {
+			r.logger.Error("outbox: relay failed", "error", err)
+			r.heartbeat.Fail(err)
+		} else {
+			if published > 0 {
+				r.logger.Debug("outbox: events published", "count", published)
+			}
+			r.heartbeat.Beat(r.interval)
+		}
+		if err == nil && published == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			r.logger.Info("outbox: relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
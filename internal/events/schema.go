@@ -0,0 +1,100 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"subscription-service/internal/domain"
+)
+
+// SchemaVersion identifies the layout of Message. Fields are only ever added
+// within a version; renaming or removing one requires a new version.
+const SchemaVersion = "subscription.event.v1"
+
+// Message is the versioned wire format of subscription events sent to
+// message brokers. It is decoupled from domain.Subscription so internal
+// refactors do not break consumers.
+type Message struct {
+	Schema         string               `json:"schema"`
+	ID             string               `json:"id"`
+	Type           string               `json:"type"`
+	SubscriptionID string               `json:"subscription_id"`
+	UserID         string               `json:"user_id"`
+	TenantID       string               `json:"tenant_id,omitempty"`
+	OccurredAt     time.Time            `json:"occurred_at"`
+	Subscription   *SubscriptionMessage `json:"subscription,omitempty"`
+}
+
+// SubscriptionMessage is the subscription state carried by Message. Dates
+// use MM-YYYY for whole months and YYYY-MM-DD for days, as in the API.
+type SubscriptionMessage struct {
+	ID           string          `json:"id"`
+	ServiceName  string          `json:"service_name"`
+	ServiceID    string          `json:"service_id,omitempty"`
+	Price        int             `json:"price"`
+	UserID       string          `json:"user_id"`
+	StartDate    string          `json:"start_date"`
+	EndDate      string          `json:"end_date,omitempty"`
+	BillingDay   *int            `json:"billing_day,omitempty"`
+	TrialEndDate string          `json:"trial_end_date,omitempty"`
+	Categories   []string        `json:"categories,omitempty"`
+	Tags         []string        `json:"tags,omitempty"`
+	Members      []MemberMessage `json:"members,omitempty"`
+	CancelReason string          `json:"cancel_reason,omitempty"`
+	CancelledAt  *time.Time      `json:"cancelled_at,omitempty"`
+}
+
+type MemberMessage struct {
+	UserID string `json:"user_id"`
+	Weight int    `json:"weight"`
+}
+
+func NewMessage(event domain.Event) Message {
+	msg := Message{
+		Schema:         SchemaVersion,
+		ID:             event.ID.String(),
+		Type:           string(event.Type),
+		SubscriptionID: event.SubscriptionID.String(),
+		UserID:         event.UserID.String(),
+		TenantID:       event.TenantID,
+		OccurredAt:     event.OccurredAt.UTC(),
+	}
+
+	sub := event.Data
+	if sub == nil {
+		return msg
+	}
+	s := &SubscriptionMessage{
+		ID:          sub.ID.String(),
+		ServiceName: sub.ServiceName,
+		Price:       sub.Price,
+		UserID:      sub.UserID.String(),
+		StartDate:   sub.StartDate.String(),
+		BillingDay:  sub.BillingDay,
+		Categories:  sub.Categories,
+		Tags:        sub.Tags,
+	}
+	if sub.ServiceID != nil {
+		s.ServiceID = sub.ServiceID.String()
+	}
+	if sub.EndDate != nil {
+		s.EndDate = sub.EndDate.String()
+	}
+	if sub.TrialEnd != nil {
+		s.TrialEndDate = sub.TrialEnd.String()
+	}
+	for _, m := range sub.Members {
+		s.Members = append(s.Members, MemberMessage{UserID: m.UserID.String(), Weight: m.Weight})
+	}
+	if sub.Cancellation != nil {
+		s.CancelReason = string(sub.Cancellation.Reason)
+		cancelledAt := sub.Cancellation.CancelledAt.UTC()
+		s.CancelledAt = &cancelledAt
+	}
+	msg.Subscription = s
+	return msg
+}
+
+func encodeMessage(event domain.Event) ([]byte, error) {
+	return json.Marshal(NewMessage(event))
+}
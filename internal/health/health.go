@@ -0,0 +1,175 @@
+// Package health runs the readiness checks of the service and tracks the
+// progress of its background workers.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check reports whether a dependency is usable. It should give up when ctx
+// is done.
+type Check func(ctx context.Context) error
+
+// Result is the outcome of one check.
+type Result struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of all checks. Status is ok only when every check
+// passed.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs named checks concurrently, each limited to timeout.
+type Checker struct {
+	timeout time.Duration
+	checks  []namedCheck
+}
+
+// NewChecker falls back to a two-second timeout when timeout is not
+// positive.
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a check. It must not be called once checks are running.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := nc.check(checkCtx)
+			res := Result{Status: StatusOK, DurationMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				res.Status = StatusFail
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[nc.name] = res
+			if err != nil {
+				report.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// staleGrace is added to the expected gap between beats before a worker
+// counts as stuck, to absorb slow iterations.
+const staleGrace = 30 * time.Second
+
+var (
+	ErrNotStarted = errors.New("worker not started")
+	ErrStopped    = errors.New("worker stopped")
+)
+
+// Heartbeat tracks a background worker. The worker beats after every loop
+// iteration that succeeded, announcing when the next beat is due, and
+// reports failed iterations with Fail; the worker is healthy while beats
+// keep coming in time. A worker that runs but keeps failing therefore turns
+// unhealthy too. A nil Heartbeat ignores beats.
+type Heartbeat struct {
+	mu      sync.Mutex
+	last    time.Time
+	next    time.Duration
+	lastErr error
+	stopped bool
+	now     func() time.Time
+}
+
+func NewHeartbeat() *Heartbeat {
+	return &Heartbeat{now: time.Now}
+}
+
+// Beat records progress. The next beat is expected within next.
+func (h *Heartbeat) Beat(next time.Duration) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = h.now()
+	h.next = next
+	h.lastErr = nil
+}
+
+// Fail records a failed iteration. It does not count as progress; the
+// error is reported once the worker misses its beat.
+func (h *Heartbeat) Fail(err error) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastErr = err
+}
+
+// Stop marks the worker as no longer running.
+func (h *Heartbeat) Stop() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopped = true
+}
+
+// Check fails when the worker never started, has stopped, or has missed
+// its next beat by twice the announced gap plus a grace period. A worker
+// that failed since its last beat is reported with that error.
+func (h *Heartbeat) Check(context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch {
+	case h.stopped:
+		return ErrStopped
+	case h.last.IsZero() && h.lastErr != nil:
+		return fmt.Errorf("%w: %v", ErrNotStarted, h.lastErr)
+	case h.last.IsZero():
+		return ErrNotStarted
+	}
+	if since := h.now().Sub(h.last); since > 2*h.next+staleGrace {
+		if h.lastErr != nil {
+			return fmt.Errorf("no progress for %s: %v", since.Round(time.Second), h.lastErr)
+		}
+		return fmt.Errorf("no progress for %s", since.Round(time.Second))
+	}
+	return nil
+}
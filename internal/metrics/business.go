@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"subscription-service/internal/domain"
+	"subscription-service/pkg/requestctx"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// businessTTL is how long business figures are reused between scrapes, as
+// computing them loads every subscription of the current month.
+const businessTTL = time.Minute
+
+type Subscriptions interface {
+	Tenants(ctx context.Context) ([]string, error)
+	EachForPeriod(ctx context.Context, filter domain.SubscriptionFilter, period domain.Period, fn func(*domain.Subscription) error) error
+}
+
+type tenantFigures struct {
+	active  int
+	revenue float64
+}
+
+// BusinessCollector reports per tenant how many subscriptions are billed
+// today and the monthly recurring revenue, the sum of what they are
+// charged this month after discounts.
+type BusinessCollector struct {
+	subscriptions Subscriptions
+	logger        *slog.Logger
+	activeDesc    *prometheus.Desc
+	revenueDesc   *prometheus.Desc
+
+	mu        sync.Mutex
+	figures   map[string]tenantFigures
+	updatedAt time.Time
+}
+
+func NewBusinessCollector(subscriptions Subscriptions, logger *slog.Logger) *BusinessCollector {
+	return &BusinessCollector{
+		subscriptions: subscriptions,
+		logger:        logger,
+		activeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "active_subscriptions"),
+			"Subscriptions billed today: started, not ended, not in trial and not paused.",
+			[]string{"tenant"}, nil,
+		),
+		revenueDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "monthly_recurring_revenue"),
+			"Sum of the current month's charges of all subscriptions after discounts.",
+			[]string{"tenant"}, nil,
+		),
+	}
+}
+
+func (c *BusinessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeDesc
+	ch <- c.revenueDesc
+}
+
+// Collect reports the cached figures, refreshing them when they are older
+// than businessTTL. When the refresh fails the previous figures are kept.
+func (c *BusinessCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.updatedAt) >= businessTTL {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		figures, err := c.compute(ctx, domain.Today().Time)
+		cancel()
+		if err != nil {
+			c.logger.Error("metrics: business figures failed", "error", err)
+		} else {
+			c.figures = figures
+			c.updatedAt = time.Now()
+		}
+	}
+
+	for tenant, f := range c.figures {
+		ch <- prometheus.MustNewConstMetric(c.activeDesc, prometheus.GaugeValue, float64(f.active), tenant)
+		ch <- prometheus.MustNewConstMetric(c.revenueDesc, prometheus.GaugeValue, f.revenue, tenant)
+	}
+}
+
+func (c *BusinessCollector) compute(ctx context.Context, today time.Time) (map[string]tenantFigures, error) {
+	tenants, err := c.subscriptions.Tenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	month := domain.MonthStart(today)
+	period := domain.Period{From: month, To: month.AddDate(0, 1, -1)}
+	figures := make(map[string]tenantFigures, len(tenants))
+	for _, tenant := range tenants {
+		var f tenantFigures
+		err := c.subscriptions.EachForPeriod(requestctx.WithTenant(ctx, tenant), domain.SubscriptionFilter{}, period, func(sub *domain.Subscription) error {
+			if sub.Billable(today) {
+				f.active++
+			}
+			f.revenue += sub.MonthlyCharge(month)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		figures[tenant] = f
+	}
+	return figures, nil
+}
@@ -0,0 +1,30 @@
+// Package notify delivers queued notifications to users.
+package notify
+
+import (
+	"context"
+	"log/slog"
+
+	"subscription-service/internal/domain"
+)
+
+// LogNotifier writes notifications to the log. It is the default channel
+// when neither SMTP nor webhook delivery is configured.
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+func NewLogNotifier(logger *slog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	n.logger.Info("notification",
+		"notification_id", notification.ID.String(),
+		"kind", string(notification.Kind),
+		"subscription_id", notification.SubscriptionID.String(),
+		"user_id", notification.UserID.String(),
+		"subject", notification.Subject(),
+	)
+	return nil
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"subscription-service/internal/domain"
+)
+
+// SMTPNotifier emails notifications. Recipient addresses are built from the
+// Recipient template, in which every {user_id} is replaced by the user ID.
+type SMTPNotifier struct {
+	addr      string
+	auth      smtp.Auth
+	from      string
+	recipient string
+}
+
+// NewSMTPNotifier authenticates with PLAIN only when a username is set, so
+// it works against unauthenticated local SMTP stand-ins.
+func NewSMTPNotifier(host, port, username, password, from, recipient string) *SMTPNotifier {
+	n := &SMTPNotifier{
+		addr:      net.JoinHostPort(host, port),
+		from:      from,
+		recipient: recipient,
+	}
+	if username != "" {
+		n.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return n
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	to := strings.ReplaceAll(n.recipient, "{user_id}", notification.UserID.String())
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", notification.Subject())
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s.\r\n\r\nPrice: %d\r\nSubscription: %s\r\n",
+		notification.Subject(), notification.Price, notification.SubscriptionID)
+
+	// net/smtp has no context support; run it aside so a hung server cannot
+	// outlive the caller's deadline.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(n.addr, n.auth, n.from, []string{to}, msg.Bytes())
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
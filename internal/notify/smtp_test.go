@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// smtpSession is what the fake server saw during one SMTP transaction.
+type smtpSession struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTP is a minimal local SMTP server. It offers neither STARTTLS nor
+// AUTH and answers RCPT with rcptReply. With hang set it accepts
+// connections but never greets.
+type fakeSMTP struct {
+	ln        net.Listener
+	rcptReply string
+	hang      bool
+	sessions  chan smtpSession
+}
+
+func startFakeSMTP(t *testing.T, rcptReply string, hang bool) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTP{ln: ln, rcptReply: rcptReply, hang: hang, sessions: make(chan smtpSession, 1)}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTP) hostPort() (string, string) {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	return host, port
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	if s.hang {
+		_, _ = conn.Read(make([]byte, 1))
+		return
+	}
+	tp := textproto.NewConn(conn)
+	var session smtpSession
+	reply := func(line string) { _ = tp.PrintfLine("%s", line) }
+
+	reply("220 localhost fake SMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			session.from = arg
+			reply("250 OK")
+		case "RCPT":
+			if !strings.HasPrefix(s.rcptReply, "2") {
+				reply(s.rcptReply)
+				continue
+			}
+			session.to = append(session.to, arg)
+			reply(s.rcptReply)
+		case "DATA":
+			reply("354 go ahead")
+			lines, err := tp.ReadDotLines()
+			if err != nil {
+				return
+			}
+			session.data = strings.Join(lines, "\n")
+			reply("250 queued")
+		case "RSET", "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			s.sessions <- session
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	userID := uuid.MustParse("7f5d6d0e-2b0c-4b7b-9d7a-3a0f1c2e4b5a")
+	notification := domain.Notification{
+		SubscriptionID: uuid.MustParse("3c1b3e4a-6f2d-4d1e-8f7a-1b2c3d4e5f60"),
+		UserID:         userID,
+		Kind:           domain.NotificationRenewal,
+		DueDate:        time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		ServiceName:    "Netflix",
+		Price:          499,
+	}
+
+	tests := []struct {
+		name      string
+		rcptReply string
+		hang      bool
+		wantErr   bool
+		wantCtx   bool
+	}{
+		{name: "delivered", rcptReply: "250 OK"},
+		{name: "recipient rejected", rcptReply: "550 no such user", wantErr: true},
+		{name: "server hangs", hang: true, wantErr: true, wantCtx: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startFakeSMTP(t, tt.rcptReply, tt.hang)
+			host, port := srv.hostPort()
+			n := NewSMTPNotifier(host, port, "", "", "billing@example.com", "{user_id}@users.example.com")
+
+			timeout := 2 * time.Second
+			if tt.hang {
+				timeout = 100 * time.Millisecond
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			err := n.Notify(ctx, notification)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantCtx && !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Notify() error = %v, want deadline exceeded", err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var session smtpSession
+			select {
+			case session = <-srv.sessions:
+			case <-time.After(2 * time.Second):
+				t.Fatal("no SMTP session recorded")
+			}
+			if want := "FROM:<billing@example.com>"; session.from != want {
+				t.Errorf("MAIL %q, want %q", session.from, want)
+			}
+			wantTo := "TO:<" + userID.String() + "@users.example.com>"
+			if len(session.to) != 1 || session.to[0] != wantTo {
+				t.Errorf("RCPT %q, want [%q]", session.to, wantTo)
+			}
+			for _, want := range []string{
+				"Subject: Netflix renews on 2025-08-01",
+				"To: " + userID.String() + "@users.example.com",
+				"Price: 499",
+				"Subscription: " + notification.SubscriptionID.String(),
+			} {
+				if !strings.Contains(session.data, want) {
+					t.Errorf("message lacks %q:\n%s", want, session.data)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package ratelimit implements token-bucket rate limits. Each key has a
+// bucket holding up to Burst tokens that refills at Rate tokens per second;
+// a request takes one token and is refused while the bucket is empty.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is the bucket size and refill rate of a token bucket.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerPeriod allows requests per period on average with bursts of up to
+// burst requests. A burst that is not positive defaults to requests.
+func PerPeriod(requests int, period time.Duration, burst int) Limit {
+	if burst <= 0 {
+		burst = requests
+	}
+	return Limit{Rate: float64(requests) / period.Seconds(), Burst: burst}
+}
+
+// Valid reports whether the limit can ever allow a request.
+func (l Limit) Valid() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result is the outcome of taking a token. Remaining is how many tokens are
+// left, Reset how long until the bucket is full again and RetryAfter, for
+// refused requests, how long until the next token is available.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Result describes a bucket left with tokens after a request that was
+// allowed or refused.
+func (l Limit) Result(tokens float64, allowed bool) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     l.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     l.refill(float64(l.Burst) - tokens),
+	}
+	if !allowed {
+		res.RetryAfter = l.refill(1 - tokens)
+	}
+	return res
+}
+
+// refill is how long the bucket takes to gain tokens.
+func (l Limit) refill(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / l.Rate * float64(time.Second))
+}
+
+// Store keeps the buckets. Take refills the bucket of key, takes a token
+// if one is available and reports the outcome.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// sweepInterval is how often MemoryStore drops buckets that are full again,
+// which behave the same as buckets never used.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	fullAt  time.Time
+}
+
+// MemoryStore keeps buckets in process memory. Limits only hold per
+// instance, so replicas should share a PostgreSQL store instead.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.fullAt) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	res := limit.Result(b.tokens, allowed)
+	b.fullAt = now.Add(res.Reset)
+	return res, nil
+}
@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPerPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		requests  int
+		period    time.Duration
+		burst     int
+		want      Limit
+		wantValid bool
+	}{
+		{name: "burst defaults to requests", requests: 60, period: time.Minute, want: Limit{Rate: 1, Burst: 60}, wantValid: true},
+		{name: "explicit burst", requests: 10, period: time.Second, burst: 20, want: Limit{Rate: 10, Burst: 20}, wantValid: true},
+		{name: "no requests", requests: 0, period: time.Minute, want: Limit{Rate: 0, Burst: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PerPeriod(tt.requests, tt.period, tt.burst)
+			if got != tt.want {
+				t.Errorf("PerPeriod() = %+v, want %+v", got, tt.want)
+			}
+			if got.Valid() != tt.wantValid {
+				t.Errorf("Valid() = %v, want %v", got.Valid(), tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	// One token per second, up to three at once.
+	limit := Limit{Rate: 1, Burst: 3}
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		key       string
+		want      Result
+		wantCount int
+	}{
+		{
+			name: "fresh bucket", key: "a",
+			want: Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second},
+		},
+		{
+			name: "second request", key: "a",
+			want: Result{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second},
+		},
+		{
+			name: "last token", key: "a",
+			want: Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second},
+		},
+		{
+			name: "empty bucket", key: "a",
+			want: Result{Allowed: false, Limit: 3, Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second},
+		},
+		{
+			name: "half a token", advance: 500 * time.Millisecond, key: "a",
+			want: Result{Allowed: false, Limit: 3, Remaining: 0, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond},
+		},
+		{
+			name: "refilled token", advance: 500 * time.Millisecond, key: "a",
+			want: Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second},
+		},
+		{
+			name: "other key has its own bucket", key: "b",
+			want: Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second},
+		},
+		{
+			name: "refill stops at the burst", advance: 10 * time.Second, key: "a",
+			want: Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second},
+		},
+		{
+			// Only the bucket just used is kept; full buckets are swept.
+			name: "sweep drops full buckets", advance: 2 * time.Minute, key: "c",
+			want:      Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second},
+			wantCount: 1,
+		},
+	}
+	for _, st := range steps {
+		now = now.Add(st.advance)
+		got, err := s.Take(context.Background(), st.key, limit)
+		if err != nil {
+			t.Fatalf("%s: Take() error = %v", st.name, err)
+		}
+		if got != st.want {
+			t.Errorf("%s: Take() = %+v, want %+v", st.name, got, st.want)
+		}
+		if st.wantCount > 0 && len(s.buckets) != st.wantCount {
+			t.Errorf("%s: %d buckets kept, want %d", st.name, len(s.buckets), st.wantCount)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Package retry is the retry policy shared by the dispatchers of queued
+// webhook deliveries and notifications: how often they poll, how long a
+// claimed batch is leased and when a failed delivery is tried again.
+package retry
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"subscription-service/internal/health"
+)
+
+// Policy configures a dispatcher. Every Interval it claims up to BatchSize
+// due deliveries and gives each Timeout to complete. A failed delivery is
+// retried after Backoff, doubling with every attempt up to MaxBackoff, and
+// is given up after MaxAttempts attempts.
+type Policy struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+}
+
+// WithDefaults returns p with every field that is not positive taken from
+// def.
+func (p Policy) WithDefaults(def Policy) Policy {
+	if p.Interval <= 0 {
+		p.Interval = def.Interval
+	}
+	if p.BatchSize <= 0 {
+		p.BatchSize = def.BatchSize
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = def.Backoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = def.MaxBackoff
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = def.Timeout
+	}
+	return p
+}
+
+// Lease is how long a claimed batch stays with the dispatcher. It leaves
+// room for every delivery in the batch to time out before the lease expires
+// and another dispatcher may claim the same rows.
+func (p Policy) Lease() time.Duration {
+	return p.Timeout * time.Duration(p.BatchSize+1)
+}
+
+// Delay doubles Backoff with every attempt, capped at MaxBackoff.
+func (p Policy) Delay(attempts int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// NextAttempt returns when a delivery that has failed attempts times in
+// total is tried again, or nil once MaxAttempts is used up.
+func (p Policy) NextAttempt(now time.Time, attempts int) *time.Time {
+	if attempts >= p.MaxAttempts {
+		return nil
+	}
+	at := now.Add(p.Delay(attempts))
+	return &at
+}
+
+// Poll calls runOnce right away and then on every Interval until ctx is
+// cancelled. Successful runs beat heartbeat, failed ones are logged and
+// reported to it. name prefixes the log messages.
+func Poll(ctx context.Context, name string, p Policy, heartbeat *health.Heartbeat, logger *slog.Logger, runOnce func(context.Context) (int, error)) {
+	logger.Info(name+": dispatcher started", "interval", p.Interval.String())
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := runOnce(ctx); err != nil {
+			logger.Error(name+": dispatch failed", "error", err)
+			heartbeat.Fail(err)
+		} else {
+			heartbeat.Beat(p.Interval + p.Lease())
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info(name + ": dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithDefaults(t *testing.T) {
+	def := Policy{Interval: time.Minute, BatchSize: 50, MaxAttempts: 5, Backoff: time.Minute, MaxBackoff: time.Hour, Timeout: 30 * time.Second}
+
+	if got := (Policy{}).WithDefaults(def); got != def {
+		t.Errorf("empty policy = %+v, want %+v", got, def)
+	}
+
+	set := Policy{Interval: time.Second, BatchSize: 10, MaxAttempts: 3, Backoff: time.Second, MaxBackoff: time.Minute, Timeout: time.Second}
+	if got := set.WithDefaults(def); got != set {
+		t.Errorf("configured policy = %+v, want %+v", got, set)
+	}
+
+	negative := Policy{BatchSize: -1, Backoff: -time.Second}
+	if got := negative.WithDefaults(def); got != def {
+		t.Errorf("negative fields = %+v, want %+v", got, def)
+	}
+}
+
+func TestLease(t *testing.T) {
+	p := Policy{BatchSize: 50, Timeout: 10 * time.Second}
+	if got, want := p.Lease(), 510*time.Second; got != want {
+		t.Errorf("Lease() = %s, want %s", got, want)
+	}
+}
+
+func TestNextAttempt(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := Policy{MaxAttempts: 8, Backoff: 30 * time.Second, MaxBackoff: 10 * time.Minute}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+		giveUp   bool
+	}{
+		{attempts: 0, want: 30 * time.Second},
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 3, want: 2 * time.Minute},
+		{attempts: 5, want: 8 * time.Minute},
+		{attempts: 6, want: 10 * time.Minute},
+		{attempts: 7, want: 10 * time.Minute},
+		{attempts: 8, giveUp: true},
+		{attempts: 50, giveUp: true},
+	}
+	for _, tt := range tests {
+		next := p.NextAttempt(now, tt.attempts)
+		if tt.giveUp {
+			if next != nil {
+				t.Errorf("NextAttempt(attempts=%d) = %s, want give up", tt.attempts, next)
+			}
+			continue
+		}
+		if next == nil {
+			t.Errorf("NextAttempt(attempts=%d) gave up, want a retry after %s", tt.attempts, tt.want)
+			continue
+		}
+		if got := next.Sub(now); got != tt.want {
+			t.Errorf("NextAttempt(attempts=%d) after %s, want %s", tt.attempts, got, tt.want)
+		}
+		if got := p.Delay(tt.attempts); got != tt.want {
+			t.Errorf("Delay(attempts=%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
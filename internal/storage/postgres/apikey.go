@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `id, name, prefix, scopes, expires_at, created_at, revoked_at, tenant_id`
+
+type APIKeyStorage struct {
+	db     *sql.DB
+	system *sql.DB
+	logger *slog.Logger
+}
+
+// NewAPIKeyStorage serves requests from db. Keys are looked up by hash
+// before the tenant is known, so GetByHash uses system, a pool whose role
+// bypasses row-level security.
+func NewAPIKeyStorage(db, system *sql.DB, logger *slog.Logger) *APIKeyStorage {
+	return &APIKeyStorage{db: db, system: system, logger: logger}
+}
+
+func (s *APIKeyStorage) Create(ctx context.Context, k *domain.APIKey, hash string) error {
+	k.ID = uuid.New()
+	k.CreatedAt = time.Now().UTC()
+	k.TenantID = tenantID(ctx)
+	s.logger.Info("CreateAPIKey started", "id", k.ID.String(), "name", k.Name)
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("CreateAPIKey begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO api_keys (id, name, prefix, key_hash, scopes, expires_at, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		k.ID, k.Name, k.Prefix, hash, pq.Array(scopeStrings(k.Scopes)), k.ExpiresAt, k.CreatedAt, k.TenantID,
+	)
+	if err != nil {
+		s.logger.Error("CreateAPIKey failed", "id", k.ID.String(), "error", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("CreateAPIKey commit failed", "id", k.ID.String(), "error", err)
+		return err
+	}
+
+	s.logger.Info("CreateAPIKey succeeded", "id", k.ID.String())
+	return nil
+}
+
+func (s *APIKeyStorage) List(ctx context.Context) ([]*domain.APIKey, error) {
+	s.logger.Info("ListAPIKeys started")
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("ListAPIKeys begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE tenant_id = $1 ORDER BY created_at`,
+		tenantID(ctx),
+	)
+	if err != nil {
+		s.logger.Error("ListAPIKeys query failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			s.logger.Error("ListAPIKeys scan failed", "error", err)
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("ListAPIKeys rows failed", "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("ListAPIKeys succeeded", "count", len(keys))
+	return keys, nil
+}
+
+// GetByHash looks a key up by the hash of its secret, whether or not it is
+// still usable. It runs before the tenant is known, so it searches all
+// tenants; the key's TenantID tells the caller which one it belongs to.
+func (s *APIKeyStorage) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	row := s.system.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash)
+	k, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		s.logger.Error("GetAPIKeyByHash failed", "error", err)
+		return nil, err
+	}
+	return k, nil
+}
+
+// Revoke disables a key immediately. Revoking a key twice keeps the first
+// revocation time.
+func (s *APIKeyStorage) Revoke(ctx context.Context, id uuid.UUID) error {
+	s.logger.Info("RevokeAPIKey started", "id", id.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("RevokeAPIKey begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1 AND tenant_id = $2`,
+		id, tenantID(ctx),
+	)
+	if err != nil {
+		s.logger.Error("RevokeAPIKey failed", "id", id.String(), "error", err)
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return domain.ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("RevokeAPIKey commit failed", "id", id.String(), "error", err)
+		return err
+	}
+
+	s.logger.Info("RevokeAPIKey succeeded", "id", id.String())
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var (
+		k      domain.APIKey
+		scopes []string
+	)
+	if err := row.Scan(&k.ID, &k.Name, &k.Prefix, pq.Array(&scopes), &k.ExpiresAt, &k.CreatedAt, &k.RevokedAt, &k.TenantID); err != nil {
+		return nil, err
+	}
+	for _, s := range scopes {
+		k.Scopes = append(k.Scopes, domain.Scope(s))
+	}
+	return &k, nil
+}
+
+func scopeStrings(scopes []domain.Scope) []string {
+	out := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		out = append(out, string(s))
+	}
+	return out
+}
@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"subscription-service/internal/domain"
+	"subscription-service/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+// loadSubscription locks the subscription row until tx ends and then reads
+// the subscription as currently visible inside tx. Writers to the same
+// subscription therefore take turns, and each sees the changes committed
+// by the one before, pauses and discounts included: the read is a separate
+// statement so it is not limited to the snapshot taken before the lock was
+// granted. Subscriptions of other tenants are not found.
+func loadSubscription(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Subscription, error) {
+	var locked uuid.UUID
+	err := tx.QueryRowContext(ctx,
+		`SELECT id FROM subscriptions WHERE id = $1 AND tenant_id = $2 FOR UPDATE`,
+		id, tenantID(ctx),
+	).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions s WHERE s.id = $1 AND s.tenant_id = $2`
+	sub, err := scanSubscription(tx.QueryRowContext(ctx, query, id, tenantID(ctx)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return sub, err
+}
+
+// recordChange writes the domain event to the outbox and an audit entry
+// with the actor and request ID from ctx, in the same transaction as the
+// change itself. before is nil for creations and after for deletions; the
+// event carries whichever is the latest state.
+func recordChange(ctx context.Context, tx *sql.Tx, eventType domain.EventType, before, after *domain.Subscription) error {
+	latest := after
+	if latest == nil {
+		latest = before
+	}
+	event := domain.NewEvent(eventType, latest)
+	event.TenantID = tenantID(ctx)
+	if err := insertOutbox(ctx, tx, event); err != nil {
+		return err
+	}
+
+	beforeJSON, err := snapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := snapshot(after)
+	if err != nil {
+		return err
+	}
+	changes, err := domain.Diff(beforeJSON, afterJSON)
+	if err != nil {
+		return err
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	var serviceNameKey *string
+	if after != nil {
+		key := domain.NormalizeServiceName(after.ServiceName)
+		serviceNameKey = &key
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO subscription_audit (id, subscription_id, action, actor, request_id, before, after, changes, changed_at, tenant_id, service_name_key)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $10, $11)`,
+		uuid.New(), latest.ID, string(eventType),
+		requestctx.Actor(ctx), requestctx.RequestID(ctx),
+		nullableJSON(beforeJSON), nullableJSON(afterJSON), changesJSON, event.OccurredAt, event.TenantID, serviceNameKey,
+	)
+	return err
+}
+
+// History returns every recorded change to the subscription, oldest first.
+// It keeps working after the subscription has been deleted.
+func (s *SubscriptionStorage) History(ctx context.Context, id uuid.UUID) ([]domain.AuditEntry, error) {
+	defer s.observe("History")()
+	s.log(ctx).Info("History subscription started", "id", id.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("History subscription begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, subscription_id, action, COALESCE(actor, ''), COALESCE(request_id, ''), before, after, changes, changed_at
+		FROM subscription_audit
+		WHERE subscription_id = $1 AND tenant_id = $2
+		ORDER BY changed_at, id`,
+		id, tenantID(ctx),
+	)
+	if err != nil {
+		s.log(ctx).Error("History subscription query failed", "id", id.String(), "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		var (
+			e             domain.AuditEntry
+			before, after []byte
+			changes       []byte
+		)
+		if err := rows.Scan(&e.ID, &e.SubscriptionID, &e.Action, &e.Actor, &e.RequestID, &before, &after, &changes, &e.ChangedAt); err != nil {
+			s.log(ctx).Error("History subscription scan failed", "id", id.String(), "error", err)
+			return nil, err
+		}
+		e.Before = before
+		e.After = after
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		s.log(ctx).Error("History subscription rows failed", "id", id.String(), "error", err)
+		return nil, err
+	}
+
+	s.log(ctx).Info("History subscription succeeded", "id", id.String(), "count", len(entries))
+	return entries, nil
+}
+
+func snapshot(sub *domain.Subscription) (json.RawMessage, error) {
+	if sub == nil {
+		return nil, nil
+	}
+	return json.Marshal(sub)
+}
+
+func nullableJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
+}
+
+// recordUpdate reloads the subscription after a change inside tx and
+// records the change against the state loaded before it.
+func recordUpdate(ctx context.Context, tx *sql.Tx, eventType domain.EventType, before *domain.Subscription) error {
+	after, err := loadSubscription(ctx, tx, before.ID)
+	if err != nil {
+		return err
+	}
+	return recordChange(ctx, tx, eventType, before, after)
+}
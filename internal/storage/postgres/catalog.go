@@ -0,0 +1,391 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const serviceColumns = `
+	s.id, s.name, COALESCE(s.category, ''), s.default_price,
+	COALESCE(array_agg(a.alias ORDER BY a.alias) FILTER (WHERE a.alias IS NOT NULL), '{}')
+`
+
+type CatalogStorage struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewCatalogStorage(db *sql.DB, logger *slog.Logger) *CatalogStorage {
+	return &CatalogStorage{db: db, logger: logger}
+}
+
+func (s *CatalogStorage) Create(ctx context.Context, svc *domain.Service) error {
+	svc.ID = uuid.New()
+	s.logger.Info("Create service started", "id", svc.ID.String(), "name", svc.Name)
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("Create service begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkNameConflicts(ctx, tx, svc); err != nil {
+		s.logger.Warn("Create service name conflict", "id", svc.ID.String(), "error", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO services (id, name, name_key, category, default_price, tenant_id) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)`,
+		svc.ID, svc.Name, domain.NormalizeServiceName(svc.Name), svc.Category, svc.DefaultPrice, tenantID(ctx),
+	)
+	if err != nil {
+		s.logger.Error("Create service failed", "id", svc.ID.String(), "error", err)
+		return mapCatalogError(err)
+	}
+
+	if err := insertAliases(ctx, tx, svc); err != nil {
+		s.logger.Error("Create service aliases failed", "id", svc.ID.String(), "error", err)
+		return mapCatalogError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("Create service commit failed", "id", svc.ID.String(), "error", err)
+		return err
+	}
+
+	s.logger.Info("Create service succeeded", "id", svc.ID.String())
+	return nil
+}
+
+func (s *CatalogStorage) GetAll(ctx context.Context) ([]*domain.Service, error) {
+	s.logger.Info("GetAll services started")
+
+	query := `SELECT ` + serviceColumns + `
+		FROM services s
+		LEFT JOIN service_aliases a ON a.service_id = s.id
+		WHERE s.tenant_id = $1
+		GROUP BY s.id
+		ORDER BY s.name`
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("GetAll services begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, tenantID(ctx))
+	if err != nil {
+		s.logger.Error("GetAll services query failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []*domain.Service
+	for rows.Next() {
+		svc, err := scanService(rows)
+		if err != nil {
+			s.logger.Error("GetAll services scan failed", "error", err)
+			return nil, err
+		}
+		services = append(services, svc)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("GetAll services rows failed", "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("GetAll services succeeded", "count", len(services))
+	return services, nil
+}
+
+func (s *CatalogStorage) GetByID(ctx context.Context, id uuid.UUID) (*domain.Service, error) {
+	s.logger.Info("GetByID service started", "id", id.String())
+
+	query := `SELECT ` + serviceColumns + `
+		FROM services s
+		LEFT JOIN service_aliases a ON a.service_id = s.id
+		WHERE s.id = $1 AND s.tenant_id = $2
+		GROUP BY s.id`
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("GetByID service begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	svc, err := scanService(tx.QueryRowContext(ctx, query, id, tenantID(ctx)))
+	if err != nil {
+		s.logger.Error("GetByID service failed", "id", id.String(), "error", err)
+		return nil, mapCatalogError(err)
+	}
+
+	s.logger.Info("GetByID service succeeded", "id", id.String())
+	return svc, nil
+}
+
+// Resolve finds the catalog service whose canonical name or one of whose
+// aliases matches name after normalization. Writes keep aliases from
+// shadowing another service's name; should legacy data still hold such a
+// pair, the canonical name wins.
+func (s *CatalogStorage) Resolve(ctx context.Context, name string) (*domain.Service, error) {
+	normalized := domain.NormalizeServiceName(name)
+	s.logger.Info("Resolve service started", "name", normalized)
+
+	query := `SELECT ` + serviceColumns + `
+		FROM services s
+		LEFT JOIN service_aliases a ON a.service_id = s.id
+		WHERE s.tenant_id = $2
+		  AND (s.name_key = $1
+		   OR s.id = (SELECT service_id FROM service_aliases WHERE tenant_id = $2 AND alias = $1))
+		GROUP BY s.id
+		ORDER BY s.name_key = $1 DESC
+		LIMIT 1`
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("Resolve service begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	svc, err := scanService(tx.QueryRowContext(ctx, query, normalized, tenantID(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.Info("Resolve service found no match", "name", normalized)
+		} else {
+			s.logger.Error("Resolve service failed", "name", normalized, "error", err)
+		}
+		return nil, mapCatalogError(err)
+	}
+
+	s.logger.Info("Resolve service succeeded", "name", normalized, "id", svc.ID.String())
+	return svc, nil
+}
+
+func (s *CatalogStorage) Update(ctx context.Context, svc *domain.Service) error {
+	s.logger.Info("Update service started", "id", svc.ID.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("Update service begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkNameConflicts(ctx, tx, svc); err != nil {
+		s.logger.Warn("Update service name conflict", "id", svc.ID.String(), "error", err)
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE services SET name = $1, name_key = $2, category = NULLIF($3, ''), default_price = $4 WHERE id = $5 AND tenant_id = $6`,
+		svc.Name, domain.NormalizeServiceName(svc.Name), svc.Category, svc.DefaultPrice, svc.ID, tenantID(ctx),
+	)
+	if err != nil {
+		s.logger.Error("Update service failed", "id", svc.ID.String(), "error", err)
+		return mapCatalogError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM service_aliases WHERE service_id = $1`, svc.ID); err != nil {
+		s.logger.Error("Update service aliases cleanup failed", "id", svc.ID.String(), "error", err)
+		return err
+	}
+	if err := insertAliases(ctx, tx, svc); err != nil {
+		s.logger.Error("Update service aliases failed", "id", svc.ID.String(), "error", err)
+		return mapCatalogError(err)
+	}
+
+	renamed, err := renameSubscriptions(ctx, tx, svc)
+	if err != nil {
+		s.logger.Error("Update service subscriptions rename failed", "id", svc.ID.String(), "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("Update service commit failed", "id", svc.ID.String(), "error", err)
+		return err
+	}
+
+	s.logger.Info("Update service succeeded", "id", svc.ID.String(), "renamed_subscriptions", renamed)
+	return nil
+}
+
+// renameSubscriptions keeps the denormalized service name of the
+// subscriptions linked to svc in sync with a rename. Each subscription is
+// changed like any other update, with an audit entry and a
+// subscription.updated event, so point-in-time reads, the change feed and
+// webhook consumers see the new name too. It returns how many were renamed.
+func renameSubscriptions(ctx context.Context, tx *sql.Tx, svc *domain.Service) (int, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id FROM subscriptions WHERE service_id = $1 AND tenant_id = $2 AND service_name <> $3 ORDER BY id`,
+		svc.ID, tenantID(ctx), svc.Name,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var renamed int
+	for _, id := range ids {
+		before, err := loadSubscription(ctx, tx, id)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return renamed, err
+		}
+		// Relinked or renamed since the rows were listed.
+		if before.ServiceID == nil || *before.ServiceID != svc.ID || before.ServiceName == svc.Name {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE subscriptions SET service_name = $1, service_name_key = $2 WHERE id = $3 AND tenant_id = $4`,
+			svc.Name, domain.NormalizeServiceName(svc.Name), id, tenantID(ctx),
+		); err != nil {
+			return renamed, err
+		}
+		if err := recordUpdate(ctx, tx, domain.EventSubscriptionUpdated, before); err != nil {
+			return renamed, err
+		}
+		renamed++
+	}
+	return renamed, nil
+}
+
+func (s *CatalogStorage) Delete(ctx context.Context, id uuid.UUID) error {
+	s.logger.Info("Delete service started", "id", id.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("Delete service begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM services WHERE id = $1 AND tenant_id = $2`, id, tenantID(ctx))
+	if err != nil {
+		s.logger.Error("Delete service failed", "id", id.String(), "error", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("Delete service commit failed", "id", id.String(), "error", err)
+		return err
+	}
+
+	s.logger.Info("Delete service succeeded", "id", id.String())
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanService(row rowScanner) (*domain.Service, error) {
+	var (
+		svc          domain.Service
+		defaultPrice sql.NullInt64
+	)
+	err := row.Scan(
+		&svc.ID,
+		&svc.Name,
+		&svc.Category,
+		&defaultPrice,
+		pq.Array(&svc.Aliases),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if defaultPrice.Valid {
+		p := int(defaultPrice.Int64)
+		svc.DefaultPrice = &p
+	}
+	return &svc, nil
+}
+
+// checkNameConflicts refuses a canonical name that is another service's
+// alias and aliases that are another service's canonical name, which would
+// make Resolve ambiguous. The unique indexes only cover each kind on its
+// own, so the tenant's catalog writes are serialized with an advisory lock
+// held until the transaction ends.
+func checkNameConflicts(ctx context.Context, tx *sql.Tx, svc *domain.Service) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('catalog:' || $1))`, tenantID(ctx)); err != nil {
+		return err
+	}
+
+	var taken bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM service_aliases WHERE tenant_id = $1 AND alias = $2 AND service_id <> $3)`,
+		tenantID(ctx), domain.NormalizeServiceName(svc.Name), svc.ID,
+	).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return domain.ErrDuplicateName
+	}
+
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM services WHERE tenant_id = $1 AND name_key = ANY($2) AND id <> $3)`,
+		tenantID(ctx), pq.Array(svc.Aliases), svc.ID,
+	).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return domain.ErrDuplicateAlias
+	}
+	return nil
+}
+
+func insertAliases(ctx context.Context, tx *sql.Tx, svc *domain.Service) error {
+	for _, alias := range svc.Aliases {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO service_aliases (alias, service_id, tenant_id) VALUES ($1, $2, $3)`,
+			alias, svc.ID, tenantID(ctx),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mapCatalogError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		if pqErr.Constraint == "services_name_idx" {
+			return domain.ErrDuplicateName
+		}
+		return domain.ErrDuplicateAlias
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"subscription-service/internal/domain"
+	"subscription-service/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+func TestCatalogRenameRecordsSubscriptionChanges(t *testing.T) {
+	db := testDB(t)
+	ctx := requestctx.WithTenant(context.Background(), "acme")
+	ctx = requestctx.WithActor(ctx, "admin")
+	ctx = requestctx.WithRequestID(ctx, "rename-1")
+
+	catalog := NewCatalogStorage(db, testLogger())
+	subscriptions := NewSubscriptionStorage(db, testLogger())
+
+	svc := &domain.Service{Name: "Netflix"}
+	if err := catalog.Create(ctx, svc); err != nil {
+		t.Fatalf("Create service: %v", err)
+	}
+	start, err := domain.ParseYearMonth("01-2025")
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked := &domain.Subscription{ServiceName: "Netflix", ServiceID: &svc.ID, Price: 400, UserID: uuid.New(), StartDate: start}
+	if err := subscriptions.Create(ctx, linked); err != nil {
+		t.Fatalf("Create linked subscription: %v", err)
+	}
+	unlinked := &domain.Subscription{ServiceName: "Netflix", Price: 400, UserID: uuid.New(), StartDate: start}
+	if err := subscriptions.Create(ctx, unlinked); err != nil {
+		t.Fatalf("Create unlinked subscription: %v", err)
+	}
+	beforeRename := time.Now()
+
+	svc.Name = "Netflix Premium"
+	if err := catalog.Update(ctx, svc); err != nil {
+		t.Fatalf("Update service: %v", err)
+	}
+
+	history, err := subscriptions.History(ctx, linked.ID)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("History has %d entries, want creation and rename", len(history))
+	}
+	rename := history[1]
+	if rename.Action != domain.EventSubscriptionUpdated {
+		t.Errorf("rename recorded as %s, want %s", rename.Action, domain.EventSubscriptionUpdated)
+	}
+	if rename.Actor != "admin" || rename.RequestID != "rename-1" {
+		t.Errorf("rename recorded by %q in %q, want admin in rename-1", rename.Actor, rename.RequestID)
+	}
+	change, ok := rename.Changes["service_name"]
+	if !ok || change.From != "Netflix" || change.To != "Netflix Premium" {
+		t.Errorf("service_name change = %+v, want Netflix -> Netflix Premium", change)
+	}
+	if len(rename.Changes) != 1 {
+		t.Errorf("rename changed %v, want only service_name", rename.Changes)
+	}
+
+	// Point-in-time reads are built from the audit snapshots.
+	now, err := subscriptions.GetRecorded(ctx, linked.ID, time.Now())
+	if err != nil {
+		t.Fatalf("GetRecorded now: %v", err)
+	}
+	if now.ServiceName != "Netflix Premium" {
+		t.Errorf("recorded name now = %q, want Netflix Premium", now.ServiceName)
+	}
+	then, err := subscriptions.GetRecorded(ctx, linked.ID, beforeRename)
+	if err != nil {
+		t.Fatalf("GetRecorded before the rename: %v", err)
+	}
+	if then.ServiceName != "Netflix" {
+		t.Errorf("recorded name before the rename = %q, want Netflix", then.ServiceName)
+	}
+
+	rows, err := db.QueryContext(ctx,
+		`SELECT payload FROM outbox WHERE subscription_id = $1 AND event_type = $2`,
+		linked.ID, string(domain.EventSubscriptionUpdated),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var events []domain.Event
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			t.Fatal(err)
+		}
+		var event domain.Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Data == nil || events[0].Data.ServiceName != "Netflix Premium" {
+		t.Errorf("outbox update events = %+v, want one carrying Netflix Premium", events)
+	}
+
+	unchanged, err := subscriptions.History(ctx, unlinked.ID)
+	if err != nil {
+		t.Fatalf("History of the unlinked subscription: %v", err)
+	}
+	if len(unchanged) != 1 {
+		t.Errorf("unlinked subscription has %d history entries, want only its creation", len(unchanged))
+	}
+}
@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func (s *SubscriptionStorage) AddDiscount(ctx context.Context, d *domain.Discount) error {
+	defer s.observe("AddDiscount")()
+	d.ID = uuid.New()
+	s.log(ctx).Info("AddDiscount started", "id", d.ID.String(), "subscription_id", d.SubscriptionID.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("AddDiscount begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := loadSubscription(ctx, tx, d.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	var endDate *time.Time
+	if d.EndDate != nil {
+		t := d.EndDate.Time
+		endDate = &t
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO subscription_discounts (id, subscription_id, kind, value, start_date, end_date, description, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)`,
+		d.ID,
+		d.SubscriptionID,
+		d.Kind,
+		d.Value,
+		d.StartDate.Time,
+		endDate,
+		d.Description,
+		tenantID(ctx),
+	)
+	if err != nil {
+		s.log(ctx).Error("AddDiscount failed", "id", d.ID.String(), "error", err)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+
+	if err := recordUpdate(ctx, tx, domain.EventSubscriptionUpdated, before); err != nil {
+		s.log(ctx).Error("AddDiscount event failed", "id", d.ID.String(), "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Error("AddDiscount commit failed", "id", d.ID.String(), "error", err)
+		return err
+	}
+
+	s.log(ctx).Info("AddDiscount succeeded", "id", d.ID.String())
+	return nil
+}
+
+func (s *SubscriptionStorage) ListDiscounts(ctx context.Context, subscriptionID uuid.UUID) ([]domain.Discount, error) {
+	defer s.observe("ListDiscounts")()
+	s.log(ctx).Info("ListDiscounts started", "subscription_id", subscriptionID.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("ListDiscounts begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, subscription_id, kind, value, start_date, end_date, COALESCE(description, '')
+		FROM subscription_discounts
+		WHERE subscription_id = $1 AND tenant_id = $2
+		ORDER BY start_date, id`,
+		subscriptionID, tenantID(ctx),
+	)
+	if err != nil {
+		s.log(ctx).Error("ListDiscounts query failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	discounts := []domain.Discount{}
+	for rows.Next() {
+		var (
+			d     domain.Discount
+			start time.Time
+			end   *time.Time
+		)
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.Kind, &d.Value, &start, &end, &d.Description); err != nil {
+			s.log(ctx).Error("ListDiscounts scan failed", "error", err)
+			return nil, err
+		}
+		d.StartDate.Time = start
+		if end != nil {
+			d.EndDate = &domain.YearMonth{Time: *end}
+		}
+		discounts = append(discounts, d)
+	}
+	if err := rows.Err(); err != nil {
+		s.log(ctx).Error("ListDiscounts rows failed", "error", err)
+		return nil, err
+	}
+
+	s.log(ctx).Info("ListDiscounts succeeded", "count", len(discounts))
+	return discounts, nil
+}
+
+func (s *SubscriptionStorage) DeleteDiscount(ctx context.Context, subscriptionID, discountID uuid.UUID) error {
+	defer s.observe("DeleteDiscount")()
+	s.log(ctx).Info("DeleteDiscount started", "id", discountID.String(), "subscription_id", subscriptionID.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("DeleteDiscount begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := loadSubscription(ctx, tx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`DELETE FROM subscription_discounts WHERE id = $1 AND subscription_id = $2 AND tenant_id = $3`,
+		discountID, subscriptionID, tenantID(ctx),
+	)
+	if err != nil {
+		s.log(ctx).Error("DeleteDiscount failed", "id", discountID.String(), "error", err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+
+	if err := recordUpdate(ctx, tx, domain.EventSubscriptionUpdated, before); err != nil {
+		s.log(ctx).Error("DeleteDiscount event failed", "id", discountID.String(), "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Error("DeleteDiscount commit failed", "id", discountID.String(), "error", err)
+		return err
+	}
+
+	s.log(ctx).Info("DeleteDiscount succeeded", "id", discountID.String())
+	return nil
+}
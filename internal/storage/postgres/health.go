@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// SchemaVersion is the migration this code expects the database to be at.
+// Bump it along with every new migration.
+const SchemaVersion = 22
+
+// HealthStorage checks that the database is reachable and migrated.
+type HealthStorage struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewHealthStorage(db *sql.DB, logger *slog.Logger) *HealthStorage {
+	return &HealthStorage{db: db, logger: logger}
+}
+
+func (s *HealthStorage) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		s.logger.Warn("database ping failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// CheckSchema fails unless the migrations recorded by golang-migrate have
+// reached SchemaVersion and the last one completed. Newer versions pass, so
+// a rollout can migrate ahead of the old replicas.
+func (s *HealthStorage) CheckSchema(ctx context.Context) error {
+	var (
+		version int
+		dirty   bool
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no migrations applied, want version %d", SchemaVersion)
+	}
+	if err != nil {
+		s.logger.Warn("schema version check failed", "error", err)
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d failed and left the schema dirty", version)
+	}
+	if version < SchemaVersion {
+		return fmt.Errorf("schema at version %d, want %d", version, SchemaVersion)
+	}
+	return nil
+}
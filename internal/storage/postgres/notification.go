@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type NotificationStorage struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewNotificationStorage(db *sql.DB, logger *slog.Logger) *NotificationStorage {
+	return &NotificationStorage{db: db, logger: logger}
+}
+
+// Enqueue stores the notifications as pending. Notifications already queued
+// for the same subscription, kind and due date are skipped, so running the
+// scheduler repeatedly is safe. They belong to the tenant in ctx. It returns
+// how many were newly queued.
+func (s *NotificationStorage) Enqueue(ctx context.Context, notifications []domain.Notification) (int, error) {
+	s.logger.Info("Enqueue notifications started", "count", len(notifications))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("Enqueue notifications begin tx failed", "error", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var queued int
+	for i := range notifications {
+		n := &notifications[i]
+		n.ID = uuid.New()
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO notifications (id, subscription_id, user_id, kind, due_date, status, tenant_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (subscription_id, kind, due_date) DO NOTHING`,
+			n.ID, n.SubscriptionID, n.UserID, n.Kind, n.DueDate, n.Status, tenantID(ctx),
+		)
+		if err != nil {
+			s.logger.Error("Enqueue notification failed", "subscription_id", n.SubscriptionID.String(), "kind", string(n.Kind), "error", err)
+			return 0, err
+		}
+		if affected, _ := res.RowsAffected(); affected > 0 {
+			queued++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("Enqueue notifications commit failed", "error", err)
+		return 0, err
+	}
+
+	s.logger.Info("Enqueue notifications succeeded", "queued", queued)
+	return queued, nil
+}
+
+// ClaimDue returns up to limit notifications whose next attempt is due and
+// leases them for lease by pushing their next attempt back, so concurrent
+// dispatchers do not deliver the same notification twice.
+func (s *NotificationStorage) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.Notification, error) {
+	s.logger.Debug("ClaimDue notifications started", "limit", limit)
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH claimed AS (
+			UPDATE notifications SET next_attempt_at = now() + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT id FROM notifications
+				WHERE status IN ('pending', 'failed') AND next_attempt_at <= now()
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT c.id, c.subscription_id, c.user_id, c.kind, c.due_date, c.status, c.attempts,
+			COALESCE(c.last_error, ''), c.next_attempt_at, c.created_at, c.sent_at,
+			s.service_name, s.price
+		FROM claimed c
+		JOIN subscriptions s ON s.id = c.subscription_id
+		ORDER BY c.due_date`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		s.logger.Error("ClaimDue notifications query failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		var n domain.Notification
+		var sentAt sql.NullTime
+		if err := rows.Scan(
+			&n.ID, &n.SubscriptionID, &n.UserID, &n.Kind, &n.DueDate, &n.Status, &n.Attempts,
+			&n.LastError, &n.NextAttemptAt, &n.CreatedAt, &sentAt,
+			&n.ServiceName, &n.Price,
+		); err != nil {
+			s.logger.Error("ClaimDue notifications scan failed", "error", err)
+			return nil, err
+		}
+		if sentAt.Valid {
+			n.SentAt = &sentAt.Time
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("ClaimDue notifications rows failed", "error", err)
+		return nil, err
+	}
+
+	s.logger.Debug("ClaimDue notifications succeeded", "count", len(notifications))
+	return notifications, nil
+}
+
+func (s *NotificationStorage) MarkSent(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = now()
+		WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		s.logger.Error("MarkSent notification failed", "id", id.String(), "error", err)
+	}
+	return err
+}
+
+// MarkFailed records a failed delivery attempt. The notification is retried
+// at nextAttempt, or moved to the dead status when nextAttempt is nil.
+func (s *NotificationStorage) MarkFailed(ctx context.Context, id uuid.UUID, reason string, nextAttempt *time.Time) error {
+	status := domain.NotificationFailed
+	retryAt := time.Now().UTC()
+	if nextAttempt == nil {
+		status = domain.NotificationDead
+	} else {
+		retryAt = *nextAttempt
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+		WHERE id = $1`,
+		id, status, reason, retryAt,
+	)
+	if err != nil {
+		s.logger.Error("MarkFailed notification failed", "id", id.String(), "error", err)
+	}
+	return err
+}
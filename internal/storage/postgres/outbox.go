@@ -0,0 +1,234 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// insertOutbox stores the event in the outbox within tx.
+func insertOutbox(ctx context.Context, tx *sql.Tx, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO outbox (id, event_type, subscription_id, user_id, payload, occurred_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		event.ID, string(event.Type), event.SubscriptionID, event.UserID, payload, event.OccurredAt, event.TenantID,
+	)
+	return err
+}
+
+type OutboxStorage struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewOutboxStorage(db *sql.DB, logger *slog.Logger) *OutboxStorage {
+	return &OutboxStorage{db: db, logger: logger}
+}
+
+// outboxLockKey is the advisory lock that lets a single relay process the
+// outbox at a time ("outbox" in ASCII).
+const outboxLockKey = 0x6f7574626f78
+
+// Process hands up to limit pending events to publish in order and marks
+// those it accepts as published. One relay processes the outbox at a time:
+// it holds a session advisory lock for the batch rather than row locks, so
+// no transaction stays open while events go out over the network, and
+// other relays return right away. Processing stops at the first failure to
+// keep events in order; the failure is recorded and retried on the next
+// call until the event has failed maxAttempts times. It is then parked as
+// dead and the events behind it go on. Events of all tenants are
+// processed; each carries its tenant. Every event that leaves the queue,
+// published or dead, gets the next publish_seq, which the change feed
+// follows. It returns how many events were published.
+func (s *OutboxStorage) Process(ctx context.Context, limit, maxAttempts int, publish func(domain.Event) error) (int, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		s.logger.Error("Process outbox conn failed", "error", err)
+		return 0, err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, outboxLockKey).Scan(&locked); err != nil {
+		s.logger.Error("Process outbox lock failed", "error", err)
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+	defer s.unlockOutbox(conn)
+
+	rows, err := conn.QueryContext(ctx, `
+		SELECT id, tenant_id, payload FROM outbox
+		WHERE published_at IS NULL AND dead_at IS NULL
+		ORDER BY seq
+		LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		s.logger.Error("Process outbox query failed", "error", err)
+		return 0, err
+	}
+
+	type pending struct {
+		id      uuid.UUID
+		tenant  string
+		payload []byte
+	}
+	var batch []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.tenant, &p.payload); err != nil {
+			rows.Close()
+			s.logger.Error("Process outbox scan failed", "error", err)
+			return 0, err
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		s.logger.Error("Process outbox rows failed", "error", err)
+		return 0, err
+	}
+
+	var published int
+	for _, p := range batch {
+		var event domain.Event
+		publishErr := json.Unmarshal(p.payload, &event)
+		event.TenantID = p.tenant
+		if publishErr == nil {
+			publishErr = publish(event)
+		}
+
+		if publishErr != nil {
+			s.logger.Warn("Process outbox publish failed", "id", p.id.String(), "error", publishErr)
+			var dead bool
+			if err := conn.QueryRowContext(ctx, `
+				UPDATE outbox
+				SET attempts = attempts + 1, last_error = $2,
+					dead_at = CASE WHEN $3 > 0 AND attempts + 1 >= $3 THEN now() END,
+					publish_seq = CASE WHEN $3 > 0 AND attempts + 1 >= $3 THEN nextval('outbox_publish_seq') END
+				WHERE id = $1
+				RETURNING dead_at IS NOT NULL`,
+				p.id, publishErr.Error(), maxAttempts,
+			).Scan(&dead); err != nil {
+				return published, err
+			}
+			if !dead {
+				break
+			}
+			s.logger.Error("Process outbox event dead", "id", p.id.String(), "attempts", maxAttempts, "error", publishErr)
+			continue
+		}
+
+		if _, err := conn.ExecContext(ctx,
+			`UPDATE outbox
+			SET published_at = now(), attempts = attempts + 1, last_error = NULL, publish_seq = nextval('outbox_publish_seq')
+			WHERE id = $1`,
+			p.id,
+		); err != nil {
+			s.logger.Error("Process outbox mark published failed", "id", p.id.String(), "error", err)
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// unlockOutbox releases the relay lock. Should that fail the connection is
+// discarded instead of going back to the pool, which ends the session and
+// with it the lock.
+func (s *OutboxStorage) unlockOutbox(conn *sql.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, outboxLockKey); err != nil {
+		s.logger.Error("Process outbox unlock failed", "error", err)
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+}
+
+// ListSince returns up to limit events that left the outbox after the given
+// publish sequence number for the tenant of ctx, in the order the relay
+// handled them. Numbers are assigned in commit order, so a reader resuming
+// from the last number it saw misses nothing. With userID set only events
+// about subscriptions the user owns or is a member of are returned.
+func (s *OutboxStorage) ListSince(ctx context.Context, after int64, userID *uuid.UUID, limit int) ([]domain.Event, error) {
+	args := []any{after, limit, tenantID(ctx)}
+	where := ""
+	if userID != nil {
+		args = append(args, userID.String())
+		where = ` AND (user_id = $4::uuid OR payload->'data'->'members' @> jsonb_build_array(jsonb_build_object('user_id', $4::text)))`
+	}
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("ListSince outbox begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT publish_seq, tenant_id, payload FROM outbox
+		WHERE publish_seq > $1 AND tenant_id = $3`+where+`
+		ORDER BY publish_seq
+		LIMIT $2`,
+		args...,
+	)
+	if err != nil {
+		s.logger.Error("ListSince outbox query failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		var (
+			seq     int64
+			tenant  string
+			payload []byte
+			event   domain.Event
+		)
+		if err := rows.Scan(&seq, &tenant, &payload); err != nil {
+			s.logger.Error("ListSince outbox scan failed", "error", err)
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &event); err != nil {
+			s.logger.Error("ListSince outbox decode failed", "seq", seq, "error", err)
+			return nil, err
+		}
+		event.Sequence = seq
+		event.TenantID = tenant
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// LastSequence is the publish sequence number of the newest event of the
+// tenant of ctx that left the outbox, or 0.
+func (s *OutboxStorage) LastSequence(ctx context.Context) (int64, error) {
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("LastSequence outbox begin tx failed", "error", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var seq int64
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(publish_seq), 0) FROM outbox WHERE tenant_id = $1`, tenantID(ctx)).Scan(&seq)
+	if err != nil {
+		s.logger.Error("LastSequence outbox query failed", "error", err)
+	}
+	return seq, err
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// AddPause stores a new pause. The subscription is locked and its pauses
+// checked again before the insert, as the caller's checks ran on an earlier
+// read: a concurrent pause may have been added since.
+func (s *SubscriptionStorage) AddPause(ctx context.Context, subscriptionID uuid.UUID, p *domain.Pause) error {
+	defer s.observe("AddPause")()
+	p.ID = uuid.New()
+	s.log(ctx).Info("AddPause started", "id", p.ID.String(), "subscription_id", subscriptionID.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("AddPause begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := loadSubscription(ctx, tx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if before.OpenPause() != nil || before.PauseOverlaps(*p) {
+		s.log(ctx).Warn("AddPause already paused", "id", p.ID.String(), "subscription_id", subscriptionID.String())
+		return domain.ErrAlreadyPaused
+	}
+	if before.EndDate != nil && p.StartDate.First().After(before.EndDate.Last()) {
+		s.log(ctx).Warn("AddPause subscription ended", "id", p.ID.String(), "subscription_id", subscriptionID.String())
+		return domain.ErrNotRunning
+	}
+
+	endDate, endHasDay := nullableDate(p.EndDate)
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO subscription_pauses (id, subscription_id, start_date, start_date_has_day, end_date, end_date_has_day, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		p.ID, subscriptionID, p.StartDate.Time, p.StartDate.HasDay, endDate, endHasDay, tenantID(ctx),
+	)
+	if err != nil {
+		s.log(ctx).Error("AddPause failed", "id", p.ID.String(), "error", err)
+		return err
+	}
+
+	if err := recordUpdate(ctx, tx, domain.EventSubscriptionUpdated, before); err != nil {
+		s.log(ctx).Error("AddPause event failed", "id", p.ID.String(), "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Error("AddPause commit failed", "id", p.ID.String(), "error", err)
+		return err
+	}
+
+	s.log(ctx).Info("AddPause succeeded", "id", p.ID.String())
+	return nil
+}
+
+// EndPause closes a pause on the given day, or removes it entirely when it
+// would end before it started.
+func (s *SubscriptionStorage) EndPause(ctx context.Context, subscriptionID, pauseID uuid.UUID, end domain.YearMonth) error {
+	defer s.observe("EndPause")()
+	s.log(ctx).Info("EndPause started", "id", pauseID.String(), "subscription_id", subscriptionID.String(), "end_date", end.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("EndPause begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := loadSubscription(ctx, tx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM subscription_pauses WHERE id = $1 AND subscription_id = $2 AND tenant_id = $3 AND start_date > $4`,
+		pauseID, subscriptionID, tenantID(ctx), end.Time,
+	)
+	if err != nil {
+		s.log(ctx).Error("EndPause delete failed", "id", pauseID.String(), "error", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE subscription_pauses SET end_date = $1, end_date_has_day = $2 WHERE id = $3 AND subscription_id = $4 AND tenant_id = $5`,
+		end.Time, end.HasDay, pauseID, subscriptionID, tenantID(ctx),
+	)
+	if err != nil {
+		s.log(ctx).Error("EndPause update failed", "id", pauseID.String(), "error", err)
+		return err
+	}
+
+	if err := recordUpdate(ctx, tx, domain.EventSubscriptionUpdated, before); err != nil {
+		s.log(ctx).Error("EndPause event failed", "id", pauseID.String(), "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Error("EndPause commit failed", "id", pauseID.String(), "error", err)
+		return err
+	}
+
+	s.log(ctx).Info("EndPause succeeded", "id", pauseID.String())
+	return nil
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"subscription-service/internal/ratelimit"
+)
+
+// pruneInterval is how often RateLimitStorage drops buckets that are full
+// again, which behave the same as buckets never used.
+const pruneInterval = time.Minute
+
+// RateLimitStorage keeps token buckets in the rate_limits table so every
+// replica enforces the same limits.
+type RateLimitStorage struct {
+	db     *sql.DB
+	logger *slog.Logger
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+func NewRateLimitStorage(db *sql.DB, logger *slog.Logger) *RateLimitStorage {
+	return &RateLimitStorage{db: db, logger: logger}
+}
+
+// Take locks the bucket of key, creating it full when missing or when it
+// has been full again since its last use, and stores what is left after
+// taking a token.
+func (s *RateLimitStorage) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("Take rate limit begin tx failed", "error", err)
+		return ratelimit.Result{}, err
+	}
+	defer tx.Rollback()
+
+	// The no-op update locks an existing row and returns it, so concurrent
+	// requests for the same key are serialized.
+	var tokens, elapsed float64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO rate_limits AS r (key, tokens, updated_at, full_at)
+		VALUES ($1, $2, now(), now())
+		ON CONFLICT (key) DO UPDATE SET key = r.key
+		RETURNING tokens, EXTRACT(EPOCH FROM now() - updated_at)`,
+		key, float64(limit.Burst),
+	).Scan(&tokens, &elapsed)
+	if err != nil {
+		s.logger.Error("Take rate limit failed", "key", key, "error", err)
+		return ratelimit.Result{}, err
+	}
+
+	tokens = math.Min(float64(limit.Burst), tokens+elapsed*limit.Rate)
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	res := limit.Result(tokens, allowed)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE rate_limits
+		SET tokens = $2, updated_at = now(), full_at = now() + make_interval(secs => $3)
+		WHERE key = $1`,
+		key, tokens, res.Reset.Seconds(),
+	)
+	if err != nil {
+		s.logger.Error("Take rate limit update failed", "key", key, "error", err)
+		return ratelimit.Result{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("Take rate limit commit failed", "key", key, "error", err)
+		return ratelimit.Result{}, err
+	}
+
+	s.pruneIfDue(ctx)
+	return res, nil
+}
+
+// pruneIfDue drops full buckets at most once per pruneInterval. Failures
+// are only logged; the rows are retried on the next run.
+func (s *RateLimitStorage) pruneIfDue(ctx context.Context) {
+	s.mu.Lock()
+	if time.Since(s.lastPruned) < pruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPruned = time.Now()
+	s.mu.Unlock()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE full_at <= now()`)
+	if err != nil {
+		s.logger.Error("Prune rate limits failed", "error", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		s.logger.Debug("Prune rate limits succeeded", "count", n)
+	}
+}
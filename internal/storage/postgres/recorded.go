@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// recordedSnapshots selects, for every subscription of tenant $2, the latest
+// audit entry recorded at or before $1. Deleted subscriptions are left out.
+const recordedSnapshots = `
+	WITH latest AS (
+		SELECT DISTINCT ON (subscription_id) subscription_id, action, after
+		FROM subscription_audit
+		WHERE changed_at <= $1 AND tenant_id = $2
+		ORDER BY subscription_id, changed_at DESC, id DESC
+	)
+	SELECT a.after FROM latest a
+	WHERE a.action <> 'subscription.deleted'
+`
+
+// GetRecorded returns the subscription as it was recorded at the given
+// moment, reconstructed from the audit log.
+func (s *SubscriptionStorage) GetRecorded(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Subscription, error) {
+	defer s.observe("GetRecorded")()
+	s.log(ctx).Info("GetRecorded subscription started", "id", id.String(), "at", at)
+
+	var after []byte
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("GetRecorded subscription begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, recordedSnapshots+` AND a.subscription_id = $3`, at, tenantID(ctx), id).Scan(&after)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.log(ctx).Warn("GetRecorded subscription not found", "id", id.String(), "at", at)
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		s.log(ctx).Error("GetRecorded subscription failed", "id", id.String(), "error", err)
+		return nil, err
+	}
+
+	var sub domain.Subscription
+	if err := json.Unmarshal(after, &sub); err != nil {
+		s.log(ctx).Error("GetRecorded subscription decode failed", "id", id.String(), "error", err)
+		return nil, err
+	}
+
+	s.log(ctx).Info("GetRecorded subscription succeeded", "id", id.String())
+	return &sub, nil
+}
+
+// ListRecorded returns the subscriptions matching filter as they were
+// recorded at the given moment. Filters apply to the recorded state, except
+// that service aliases are resolved against the current catalog.
+func (s *SubscriptionStorage) ListRecorded(ctx context.Context, filter domain.SubscriptionFilter, at time.Time) ([]*domain.Subscription, error) {
+	defer s.observe("ListRecorded")()
+	s.log(ctx).Info("ListRecorded subscriptions started", "at", at)
+	logFilter(s.log(ctx), filter)
+
+	where, args := recordedFilterClause(filter, []any{at, tenantID(ctx)})
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("ListRecorded subscriptions begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, recordedSnapshots+where, args...)
+	if err != nil {
+		s.log(ctx).Error("ListRecorded subscriptions query failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*domain.Subscription
+	for rows.Next() {
+		var after []byte
+		if err := rows.Scan(&after); err != nil {
+			s.log(ctx).Error("ListRecorded subscriptions scan failed", "error", err)
+			return nil, err
+		}
+		var sub domain.Subscription
+		if err := json.Unmarshal(after, &sub); err != nil {
+			s.log(ctx).Error("ListRecorded subscriptions decode failed", "error", err)
+			return nil, err
+		}
+		subs = append(subs, &sub)
+	}
+	if err := rows.Err(); err != nil {
+		s.log(ctx).Error("ListRecorded subscriptions rows failed", "error", err)
+		return nil, err
+	}
+
+	s.log(ctx).Info("ListRecorded subscriptions succeeded", "count", len(subs))
+	return subs, nil
+}
+
+// recordedFilterClause is filterClause for JSON snapshots in a.after. The
+// tenant is expected in $2, as in recordedSnapshots.
+func recordedFilterClause(filter domain.SubscriptionFilter, args []any) (string, []any) {
+	var b strings.Builder
+
+	if filter.UserID != nil {
+		args = append(args, filter.UserID.String())
+		fmt.Fprintf(&b, ` AND (
+			a.after->>'user_id' = $%[1]d
+			OR a.after->'members' @> jsonb_build_array(jsonb_build_object('user_id', $%[1]d::text))
+		)`, len(args))
+	}
+
+	if filter.ServiceName != nil {
+		args = append(args, domain.NormalizeServiceName(*filter.ServiceName))
+		fmt.Fprintf(&b, ` AND (
+			a.service_name_key = $%[1]d
+			OR (a.after->>'service_id')::uuid IN (
+				SELECT id FROM services WHERE tenant_id = $2 AND name_key = $%[1]d
+				UNION
+				SELECT service_id FROM service_aliases WHERE tenant_id = $2 AND alias = $%[1]d
+			)
+		)`, len(args))
+	}
+
+	if len(filter.Categories) > 0 {
+		args = append(args, pq.Array(filter.Categories))
+		fmt.Fprintf(&b, ` AND COALESCE(a.after->'categories', '[]') ?| $%d`, len(args))
+	}
+
+	if len(filter.Tags) > 0 {
+		args = append(args, pq.Array(filter.Tags))
+		fmt.Fprintf(&b, ` AND COALESCE(a.after->'tags', '[]') ?& $%d`, len(args))
+	}
+
+	return b.String(), args
+}
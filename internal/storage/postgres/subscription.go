@@ -3,224 +3,764 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"subscription-service/internal/domain"
+	"subscription-service/pkg/logger"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const subscriptionColumns = `
+	s.id, s.service_name, s.service_id, s.price, s.user_id,
+	s.start_date, s.start_date_has_day, s.end_date, s.end_date_has_day, s.billing_day,
+	s.cancel_reason, COALESCE(s.cancel_comment, ''), s.cancelled_at,
+	s.trial_end_date, s.trial_end_date_has_day,
+	ARRAY(
+		SELECT c.name FROM subscription_categories sc
+		JOIN categories c ON c.id = sc.category_id
+		WHERE sc.subscription_id = s.id ORDER BY c.name
+	),
+	ARRAY(
+		SELECT t.name FROM subscription_tags st
+		JOIN tags t ON t.id = st.tag_id
+		WHERE st.subscription_id = s.id ORDER BY t.name
+	),
+	COALESCE((
+		SELECT json_agg(json_build_object('user_id', m.user_id, 'weight', m.weight) ORDER BY m.user_id)
+		FROM subscription_members m WHERE m.subscription_id = s.id
+	), '[]'),
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'id', d.id, 'subscription_id', d.subscription_id, 'kind', d.kind, 'value', d.value,
+			'start_date', to_char(d.start_date, 'MM-YYYY'), 'end_date', to_char(d.end_date, 'MM-YYYY'),
+			'description', d.description
+		) ORDER BY d.start_date, d.id)
+		FROM subscription_discounts d WHERE d.subscription_id = s.id
+	), '[]'),
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'id', p.id,
+			'start_date', to_char(p.start_date, CASE WHEN p.start_date_has_day THEN 'YYYY-MM-DD' ELSE 'MM-YYYY' END),
+			'end_date', to_char(p.end_date, CASE WHEN p.end_date_has_day THEN 'YYYY-MM-DD' ELSE 'MM-YYYY' END)
+		) ORDER BY p.start_date)
+		FROM subscription_pauses p WHERE p.subscription_id = s.id
+	), '[]')
+`
+
 type SubscriptionStorage struct {
-	db     *sql.DB
-	logger *slog.Logger
+	db       *sql.DB
+	logger   *slog.Logger
+	observer QueryObserver
 }
 
 func NewSubscriptionStorage(db *sql.DB, logger *slog.Logger) *SubscriptionStorage {
 	return &SubscriptionStorage{db: db, logger: logger}
 }
 
+// log returns the request logger carried by ctx, falling back to the
+// storage logger for the background workers.
+func (s *SubscriptionStorage) log(ctx context.Context) *slog.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
+
+// QueryObserver records how long storage operations take.
+type QueryObserver interface {
+	ObserveQuery(operation string, duration time.Duration)
+}
+
+// SetQueryObserver reports the latency of every operation to o.
+func (s *SubscriptionStorage) SetQueryObserver(o QueryObserver) {
+	s.observer = o
+}
+
+// observe starts timing operation; the returned function records it.
+func (s *SubscriptionStorage) observe(operation string) func() {
+	start := time.Now()
+	return func() {
+		if s.observer != nil {
+			s.observer.ObserveQuery(operation, time.Since(start))
+		}
+	}
+}
+
 func (s *SubscriptionStorage) Create(ctx context.Context, sub *domain.Subscription) error {
+	defer s.observe("Create")()
 	sub.ID = uuid.New()
-	s.logger.Info("Create subscription started", "id", sub.ID.String(), "service_name", sub.ServiceName, "user_id", sub.UserID.String())
+	s.log(ctx).Info("Create subscription started", "id", sub.ID.String(), "service_name", sub.ServiceName, "user_id", sub.UserID.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("Create subscription begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
 
 	query := `
-		INSERT INTO subscriptions (id, service_name, price, user_id, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO subscriptions (
+			id, service_name, service_id, price, user_id,
+			start_date, start_date_has_day, end_date, end_date_has_day, billing_day,
+			trial_end_date, trial_end_date_has_day, tenant_id, service_name_key
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
-	var endDate *time.Time
+	var (
+		endDate   *time.Time
+		endHasDay bool
+	)
 	if sub.EndDate != nil {
 		t := sub.EndDate.Time
 		endDate = &t
+		endHasDay = sub.EndDate.HasDay
 	}
-	_, err := s.db.ExecContext(
+	trialEnd, trialEndHasDay := nullableDate(sub.TrialEnd)
+	_, err = tx.ExecContext(
 		ctx,
 		query,
 		sub.ID,
 		sub.ServiceName,
+		sub.ServiceID,
 		sub.Price,
 		sub.UserID,
 		sub.StartDate.Time,
+		sub.StartDate.HasDay,
 		endDate,
+		endHasDay,
+		sub.BillingDay,
+		trialEnd,
+		trialEndHasDay,
+		tenantID(ctx),
+		domain.NormalizeServiceName(sub.ServiceName),
 	)
 	if err != nil {
-		s.logger.Error("Create subscription failed", "id", sub.ID.String(), "error", err)
+		s.log(ctx).Error("Create subscription failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+
+	if err := replaceLabels(ctx, tx, sub); err != nil {
+		s.log(ctx).Error("Create subscription labels failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+
+	if err := replaceMembers(ctx, tx, sub); err != nil {
+		s.log(ctx).Error("Create subscription members failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+
+	created, err := loadSubscription(ctx, tx, sub.ID)
+	if err != nil {
+		s.log(ctx).Error("Create subscription reload failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+	if err := recordChange(ctx, tx, domain.EventSubscriptionCreated, nil, created); err != nil {
+		s.log(ctx).Error("Create subscription event failed", "id", sub.ID.String(), "error", err)
 		return err
 	}
 
-	s.logger.Info("Create subscription succeeded", "id", sub.ID.String())
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Error("Create subscription commit failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+
+	s.log(ctx).Info("Create subscription succeeded", "id", sub.ID.String())
 	return nil
 }
 
-func (s *SubscriptionStorage) GetAll(ctx context.Context) ([]*domain.Subscription, error) {
-	s.logger.Info("GetAll subscriptions started")
+func (s *SubscriptionStorage) GetAll(ctx context.Context, filter domain.SubscriptionFilter) ([]*domain.Subscription, error) {
+	defer s.observe("GetAll")()
+	s.log(ctx).Info("GetAll subscriptions started")
+
+	where, args := filterClause(filter, []any{tenantID(ctx)})
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions s WHERE s.tenant_id = $1` + where
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("GetAll subscriptions begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	query := `SELECT id, service_name, price, user_id, start_date, end_date FROM subscriptions`
-	rows, err := s.db.QueryContext(ctx, query)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
-		s.logger.Error("GetAll subscriptions query failed", "error", err)
+		s.log(ctx).Error("GetAll subscriptions query failed", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
 
 	var subs []*domain.Subscription
 	for rows.Next() {
-		var (
-			start time.Time
-			end   *time.Time
-		)
-
-		sub := new(domain.Subscription)
-		err := rows.Scan(
-			&sub.ID,
-			&sub.ServiceName,
-			&sub.Price,
-			&sub.UserID,
-			&start,
-			&end,
-		)
+		sub, err := scanSubscription(rows)
 		if err != nil {
-			s.logger.Error("GetAll subscriptions scan failed", "error", err)
+			s.log(ctx).Error("GetAll subscriptions scan failed", "error", err)
 			return nil, err
 		}
 
-		sub.StartDate.Time = start
-		if end != nil {
-			sub.EndDate = &domain.YearMonth{Time: *end}
-		}
-
 		subs = append(subs, sub)
 	}
 
-	s.logger.Info("GetAll subscriptions succeeded", "count", len(subs))
+	s.log(ctx).Info("GetAll subscriptions succeeded", "count", len(subs))
 	return subs, nil
 }
 
-
 func (s *SubscriptionStorage) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
-	s.logger.Info("GetByID subscription started", "id", id.String())
-
-	query := `SELECT id, service_name, price, user_id, start_date, end_date FROM subscriptions WHERE id = $1`
+	defer s.observe("GetByID")()
+	s.log(ctx).Info("GetByID subscription started", "id", id.String())
 
-	var (
-		start time.Time
-		end   *time.Time
-	)
-
-	sub := new(domain.Subscription)
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions s WHERE s.id = $1 AND s.tenant_id = $2`
 
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&sub.ID,
-		&sub.ServiceName,
-		&sub.Price,
-		&sub.UserID,
-		&start,
-		&end,
-	)
+	tx, err := beginTenantTx(ctx, s.db)
 	if err != nil {
-		s.logger.Error("GetByID subscription failed", "id", id.String(), "error", err)
+		s.log(ctx).Error("GetByID subscription begin tx failed", "error", err)
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	sub.StartDate.Time = start
-
-	if end != nil {
-		sub.EndDate = &domain.YearMonth{Time: *end}
+	sub, err := scanSubscription(tx.QueryRowContext(ctx, query, id, tenantID(ctx)))
+	if err != nil {
+		s.log(ctx).Error("GetByID subscription failed", "id", id.String(), "error", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
 	}
 
-	s.logger.Info("GetByID subscription succeeded", "id", id.String())
+	s.log(ctx).Info("GetByID subscription succeeded", "id", id.String())
 	return sub, nil
 }
 
-
 func (s *SubscriptionStorage) Update(ctx context.Context, sub *domain.Subscription) error {
-	s.logger.Info("Update subscription started", "id", sub.ID.String())
+	defer s.observe("Update")()
+	s.log(ctx).Info("Update subscription started", "id", sub.ID.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("Update subscription begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := loadSubscription(ctx, tx, sub.ID)
+	if err != nil {
+		s.log(ctx).Error("Update subscription load failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+	// A cancellation may have been committed since the caller checked.
+	if !before.AllowsEndDate(sub.EndDate) {
+		s.log(ctx).Warn("Update subscription end date of a cancelled subscription", "id", sub.ID.String())
+		return domain.ErrCancelledEndDate
+	}
 
 	query := `
 		UPDATE subscriptions
-		SET service_name = $1, price = $2, start_date = $3, end_date = $4
-		WHERE id = $5
+		SET service_name = $1, service_id = $2, price = $3,
+			start_date = $4, start_date_has_day = $5, end_date = $6, end_date_has_day = $7, billing_day = $8,
+			trial_end_date = $9, trial_end_date_has_day = $10, service_name_key = $13
+		WHERE id = $11 AND tenant_id = $12
 	`
 
-	var endDate *time.Time
+	var (
+		endDate   *time.Time
+		endHasDay bool
+	)
 	if sub.EndDate != nil {
 		t := sub.EndDate.Time
 		endDate = &t
+		endHasDay = sub.EndDate.HasDay
 	}
+	trialEnd, trialEndHasDay := nullableDate(sub.TrialEnd)
 
-	_, err := s.db.ExecContext(
+	_, err = tx.ExecContext(
 		ctx,
 		query,
 		sub.ServiceName,
+		sub.ServiceID,
 		sub.Price,
 		sub.StartDate.Time,
+		sub.StartDate.HasDay,
 		endDate,
+		endHasDay,
+		sub.BillingDay,
+		trialEnd,
+		trialEndHasDay,
 		sub.ID,
+		tenantID(ctx),
+		domain.NormalizeServiceName(sub.ServiceName),
 	)
 	if err != nil {
-		s.logger.Error("Update subscription failed", "id", sub.ID.String(), "error", err)
+		s.log(ctx).Error("Update subscription failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+
+	if err := replaceLabels(ctx, tx, sub); err != nil {
+		s.log(ctx).Error("Update subscription labels failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+
+	if err := replaceMembers(ctx, tx, sub); err != nil {
+		s.log(ctx).Error("Update subscription members failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+
+	if err := recordUpdate(ctx, tx, domain.EventSubscriptionUpdated, before); err != nil {
+		s.log(ctx).Error("Update subscription event failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Error("Update subscription commit failed", "id", sub.ID.String(), "error", err)
+		return err
+	}
+
+	s.log(ctx).Info("Update subscription succeeded", "id", sub.ID.String())
+	return nil
+}
+
+// Cancel sets the end date of a subscription and records the cancellation.
+func (s *SubscriptionStorage) Cancel(ctx context.Context, id uuid.UUID, endDate domain.YearMonth, c domain.Cancellation) error {
+	defer s.observe("Cancel")()
+	s.log(ctx).Info("Cancel subscription started", "id", id.String(), "end_date", endDate.String(), "reason", string(c.Reason))
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("Cancel subscription begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := loadSubscription(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE subscriptions
+		SET end_date = $1, end_date_has_day = $2, cancel_reason = $3, cancel_comment = NULLIF($4, ''), cancelled_at = $5
+		WHERE id = $6 AND tenant_id = $7 AND cancelled_at IS NULL
+	`
+	res, err := tx.ExecContext(ctx, query, endDate.Time, endDate.HasDay, c.Reason, c.Comment, c.CancelledAt, id, tenantID(ctx))
+	if err != nil {
+		s.log(ctx).Error("Cancel subscription failed", "id", id.String(), "error", err)
+		return err
+	}
+	// A concurrent cancellation got there first.
+	if n, err := res.RowsAffected(); err != nil {
 		return err
+	} else if n == 0 {
+		s.log(ctx).Warn("Cancel subscription already cancelled", "id", id.String())
+		return domain.ErrAlreadyCancelled
 	}
 
-	s.logger.Info("Update subscription succeeded", "id", sub.ID.String())
+	if err := recordUpdate(ctx, tx, domain.EventSubscriptionCancelled, before); err != nil {
+		s.log(ctx).Error("Cancel subscription event failed", "id", id.String(), "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Error("Cancel subscription commit failed", "id", id.String(), "error", err)
+		return err
+	}
+
+	s.log(ctx).Info("Cancel subscription succeeded", "id", id.String())
 	return nil
 }
 
+// Delete removes the subscription. The deletion event carries the
+// subscription as it was right before.
 func (s *SubscriptionStorage) Delete(ctx context.Context, id uuid.UUID) error {
-	s.logger.Info("Delete subscription started", "id", id.String())
+	defer s.observe("Delete")()
+	s.log(ctx).Info("Delete subscription started", "id", id.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("Delete subscription begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
 
-	query := `DELETE FROM subscriptions WHERE id = $1`
-	_, err := s.db.ExecContext(ctx, query, id)
+	before, err := loadSubscription(ctx, tx, id)
 	if err != nil {
-		s.logger.Error("Delete subscription failed", "id", id.String(), "error", err)
 		return err
 	}
 
-	s.logger.Info("Delete subscription succeeded", "id", id.String())
+	query := `DELETE FROM subscriptions WHERE id = $1 AND tenant_id = $2`
+	_, err = tx.ExecContext(ctx, query, id, tenantID(ctx))
+	if err != nil {
+		s.log(ctx).Error("Delete subscription failed", "id", id.String(), "error", err)
+		return err
+	}
+
+	if err := recordChange(ctx, tx, domain.EventSubscriptionDeleted, before, nil); err != nil {
+		s.log(ctx).Error("Delete subscription event failed", "id", id.String(), "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log(ctx).Error("Delete subscription commit failed", "id", id.String(), "error", err)
+		return err
+	}
+
+	s.log(ctx).Info("Delete subscription succeeded", "id", id.String())
 	return nil
 }
 
-func (s *SubscriptionStorage) TotalCost(
+// periodPageSize is how many subscriptions EachForPeriod reads per query.
+const periodPageSize = 500
+
+// EachForPeriod calls fn for every subscription matching filter whose
+// lifetime overlaps period, with its members and discounts loaded for cost
+// calculations. Rows are read in pages ordered by ID, so memory use stays
+// bounded however many subscriptions match. An error from fn stops the
+// iteration and is returned.
+func (s *SubscriptionStorage) EachForPeriod(
 	ctx context.Context,
-	userID *uuid.UUID,
-	serviceName *string,
-	from, to time.Time,
-) (int64, error) {
-	s.logger.Info("TotalCost calculation started", "from", from.Format("01-2006"), "to", to.Format("01-2006"))
-	if userID != nil {
-		s.logger.Info("TotalCost filter by userID", "userID", userID.String())
+	filter domain.SubscriptionFilter,
+	period domain.Period,
+	fn func(*domain.Subscription) error,
+) error {
+	defer s.observe("EachForPeriod")()
+	s.log(ctx).Info("EachForPeriod subscriptions started", "from", period.From.Format("2006-01-02"), "to", period.To.Format("2006-01-02"))
+	logFilter(s.log(ctx), filter)
+
+	var (
+		after uuid.UUID
+		count int
+	)
+	for {
+		page, err := s.periodPage(ctx, filter, period, after)
+		if err != nil {
+			s.log(ctx).Error("EachForPeriod subscriptions page failed", "error", err)
+			return err
+		}
+		for _, sub := range page {
+			if err := fn(sub); err != nil {
+				return err
+			}
+		}
+		count += len(page)
+		if len(page) < periodPageSize {
+			break
+		}
+		after = page[len(page)-1].ID
 	}
-	if serviceName != nil {
-		s.logger.Info("TotalCost filter by serviceName", "serviceName", *serviceName)
+
+	s.log(ctx).Info("EachForPeriod subscriptions succeeded", "count", count)
+	return nil
+}
+
+// periodPage reads the next page of EachForPeriod after the given ID.
+func (s *SubscriptionStorage) periodPage(
+	ctx context.Context,
+	filter domain.SubscriptionFilter,
+	period domain.Period,
+	after uuid.UUID,
+) ([]*domain.Subscription, error) {
+	// Month-precision end dates are stored as the first of their month but
+	// cover the whole month, hence the comparison against the month start.
+	where, args := filterClause(filter, []any{period.From, period.To, tenantID(ctx), after, periodPageSize})
+	query := `SELECT ` + subscriptionColumns + `
+		FROM subscriptions s
+		WHERE s.start_date <= $2 AND (s.end_date IS NULL OR s.end_date >= date_trunc('month', $1::date))
+			AND s.tenant_id = $3 AND s.id > $4
+	` + where + `
+		ORDER BY s.id
+		LIMIT $5`
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	query := `
-		SELECT COALESCE(SUM(price), 0)
-		FROM subscriptions
-		WHERE start_date >= $1 AND start_date <= $2
-	`
-	args := []interface{}{from, to}
-	argIdx := 3
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := make([]*domain.Subscription, 0, periodPageSize)
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// Tenants returns every tenant that has subscriptions, for background jobs
+// that work through the tenants one at a time. It reads across tenants, so
+// the storage must use a pool whose role bypasses row-level security.
+func (s *SubscriptionStorage) Tenants(ctx context.Context) ([]string, error) {
+	defer s.observe("Tenants")()
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT tenant_id FROM subscriptions ORDER BY tenant_id`)
+	if err != nil {
+		s.log(ctx).Error("Tenants query failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
 
-	if userID != nil {
-		query += fmt.Sprintf(" AND user_id = $%d", argIdx)
-		args = append(args, *userID)
-		argIdx++
+	var tenants []string
+	for rows.Next() {
+		var tenant string
+		if err := rows.Scan(&tenant); err != nil {
+			s.log(ctx).Error("Tenants scan failed", "error", err)
+			return nil, err
+		}
+		tenants = append(tenants, tenant)
 	}
+	return tenants, rows.Err()
+}
 
-	if serviceName != nil {
-		query += fmt.Sprintf(" AND service_name = $%d", argIdx)
-		args = append(args, *serviceName)
+func (s *SubscriptionStorage) ListCategories(ctx context.Context) ([]string, error) {
+	defer s.observe("ListCategories")()
+	return s.listNames(ctx, "categories")
+}
+
+func (s *SubscriptionStorage) ListTags(ctx context.Context) ([]string, error) {
+	defer s.observe("ListTags")()
+	return s.listNames(ctx, "tags")
+}
+
+func (s *SubscriptionStorage) listNames(ctx context.Context, table string) ([]string, error) {
+	s.log(ctx).Info("List names started", "table", table)
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.log(ctx).Error("List names begin tx failed", "error", err)
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	var total int64
-	err := s.db.QueryRowContext(ctx, query, args...).Scan(&total)
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM `+table+` WHERE tenant_id = $1 ORDER BY name`, tenantID(ctx))
+	if err != nil {
+		s.log(ctx).Error("List names query failed", "table", table, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			s.log(ctx).Error("List names scan failed", "table", table, "error", err)
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func scanSubscription(row rowScanner) (*domain.Subscription, error) {
+	var (
+		start          time.Time
+		end            *time.Time
+		endHasDay      bool
+		billingDay     sql.NullInt32
+		cancelReason   sql.NullString
+		cancelComment  string
+		cancelledAt    sql.NullTime
+		trialEnd       *time.Time
+		trialEndHasDay bool
+		members        []byte
+		discounts      []byte
+		pauses         []byte
+	)
+
+	sub := new(domain.Subscription)
+	err := row.Scan(
+		&sub.ID,
+		&sub.ServiceName,
+		&sub.ServiceID,
+		&sub.Price,
+		&sub.UserID,
+		&start,
+		&sub.StartDate.HasDay,
+		&end,
+		&endHasDay,
+		&billingDay,
+		&cancelReason,
+		&cancelComment,
+		&cancelledAt,
+		&trialEnd,
+		&trialEndHasDay,
+		pq.Array(&sub.Categories),
+		pq.Array(&sub.Tags),
+		&members,
+		&discounts,
+		&pauses,
+	)
 	if err != nil {
-		s.logger.Error("TotalCost calculation failed", "error", err)
-		return 0, err
+		return nil, err
+	}
+
+	if err := json.Unmarshal(members, &sub.Members); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(discounts, &sub.Discounts); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(pauses, &sub.Pauses); err != nil {
+		return nil, err
+	}
+
+	sub.StartDate.Time = start
+	if end != nil {
+		sub.EndDate = &domain.YearMonth{Time: *end, HasDay: endHasDay}
+	}
+	if billingDay.Valid {
+		day := int(billingDay.Int32)
+		sub.BillingDay = &day
+	}
+	if trialEnd != nil {
+		sub.TrialEnd = &domain.YearMonth{Time: *trialEnd, HasDay: trialEndHasDay}
+	}
+	if cancelReason.Valid {
+		sub.Cancellation = &domain.Cancellation{
+			Reason:      domain.CancelReason(cancelReason.String),
+			Comment:     cancelComment,
+			CancelledAt: cancelledAt.Time,
+		}
+	}
+	return sub, nil
+}
+
+// filterClause renders the optional listing filters as additional AND
+// conditions on the subscriptions table aliased as s. Placeholders continue
+// after the arguments already present in args.
+func filterClause(filter domain.SubscriptionFilter, args []any) (string, []any) {
+	var b strings.Builder
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		fmt.Fprintf(&b, ` AND (
+			s.user_id = $%[1]d
+			OR EXISTS (SELECT 1 FROM subscription_members m WHERE m.subscription_id = s.id AND m.user_id = $%[1]d)
+		)`, len(args))
+	}
+
+	if filter.ServiceName != nil {
+		// Match the catalog entry behind any of its aliases as well as
+		// free-text names that were never linked to the catalog.
+		args = append(args, domain.NormalizeServiceName(*filter.ServiceName))
+		fmt.Fprintf(&b, ` AND (
+			s.service_name_key = $%[1]d
+			OR s.service_id IN (
+				SELECT id FROM services WHERE tenant_id = s.tenant_id AND name_key = $%[1]d
+				UNION
+				SELECT service_id FROM service_aliases WHERE tenant_id = s.tenant_id AND alias = $%[1]d
+			)
+		)`, len(args))
+	}
+
+	if len(filter.Categories) > 0 {
+		args = append(args, pq.Array(filter.Categories))
+		fmt.Fprintf(&b, ` AND EXISTS (
+			SELECT 1 FROM subscription_categories sc
+			JOIN categories c ON c.id = sc.category_id
+			WHERE sc.subscription_id = s.id AND c.name = ANY($%d)
+		)`, len(args))
+	}
+
+	for _, tag := range filter.Tags {
+		args = append(args, tag)
+		fmt.Fprintf(&b, ` AND EXISTS (
+			SELECT 1 FROM subscription_tags st
+			JOIN tags t ON t.id = st.tag_id
+			WHERE st.subscription_id = s.id AND t.name = $%d
+		)`, len(args))
+	}
+
+	return b.String(), args
+}
+
+func logFilter(logger *slog.Logger, filter domain.SubscriptionFilter) {
+	if filter.UserID != nil {
+		logger.Info("filter by userID", "userID", filter.UserID.String())
+	}
+	if filter.ServiceName != nil {
+		logger.Info("filter by serviceName", "serviceName", *filter.ServiceName)
+	}
+	if len(filter.Categories) > 0 {
+		logger.Info("filter by categories", "categories", filter.Categories)
+	}
+	if len(filter.Tags) > 0 {
+		logger.Info("filter by tags", "tags", filter.Tags)
+	}
+}
+
+// replaceLabels rewrites the category and tag links of a subscription.
+// Unknown category and tag names are added to the tenant's tables on the
+// fly.
+func replaceLabels(ctx context.Context, tx *sql.Tx, sub *domain.Subscription) error {
+	tenant := tenantID(ctx)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM subscription_categories WHERE subscription_id = $1`, sub.ID); err != nil {
+		return err
+	}
+	for _, name := range sub.Categories {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO categories (id, name, tenant_id) VALUES ($1, $2, $3) ON CONFLICT (tenant_id, name) DO NOTHING`,
+			uuid.New(), name, tenant,
+		); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO subscription_categories (subscription_id, category_id, tenant_id)
+			SELECT $1, id, tenant_id FROM categories WHERE tenant_id = $3 AND name = $2
+			ON CONFLICT DO NOTHING`,
+			sub.ID, name, tenant,
+		); err != nil {
+			return err
+		}
 	}
 
-	s.logger.Info("TotalCost calculation succeeded", "total", total)
-	return total, nil
+	if _, err := tx.ExecContext(ctx, `DELETE FROM subscription_tags WHERE subscription_id = $1`, sub.ID); err != nil {
+		return err
+	}
+	for _, name := range sub.Tags {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO tags (id, name, tenant_id) VALUES ($1, $2, $3) ON CONFLICT (tenant_id, name) DO NOTHING`,
+			uuid.New(), name, tenant,
+		); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO subscription_tags (subscription_id, tag_id, tenant_id)
+			SELECT $1, id, tenant_id FROM tags WHERE tenant_id = $3 AND name = $2
+			ON CONFLICT DO NOTHING`,
+			sub.ID, name, tenant,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func replaceMembers(ctx context.Context, tx *sql.Tx, sub *domain.Subscription) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM subscription_members WHERE subscription_id = $1`, sub.ID); err != nil {
+		return err
+	}
+	for _, m := range sub.Members {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO subscription_members (subscription_id, user_id, weight, tenant_id) VALUES ($1, $2, $3, $4)`,
+			sub.ID, m.UserID, m.Weight, tenantID(ctx),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nullableDate splits an optional date into its column values.
+func nullableDate(ym *domain.YearMonth) (*time.Time, bool) {
+	if ym == nil {
+		return nil, false
+	}
+	t := ym.Time
+	return &t, ym.HasDay
 }
@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"subscription-service/internal/domain"
+	"subscription-service/pkg/requestctx"
+)
+
+// tenantID returns the tenant every query made for ctx is limited to.
+// Callers that resolved none act for the default tenant.
+func tenantID(ctx context.Context) string {
+	if tenant := requestctx.Tenant(ctx); tenant != "" {
+		return tenant
+	}
+	return domain.DefaultTenant
+}
+
+// beginTenantTx starts a transaction limited to the tenant of ctx. Besides
+// the explicit tenant conditions in every query, it sets app.tenant_id so
+// the row-level security policies apply when they are in force. Every query
+// made for a request runs in one, reads included, because the policies
+// refuse all rows to sessions without app.tenant_id. Work across tenants
+// belongs on a pool whose role bypasses row-level security.
+func beginTenantTx(ctx context.Context, db *sql.DB) (*sql.Tx, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('app.tenant_id', $1, true)`, tenantID(ctx)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,392 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const deliveryColumns = `
+	d.id, d.endpoint_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.response_status, COALESCE(d.last_error, ''), d.next_attempt_at, d.created_at, d.delivered_at
+`
+
+type WebhookStorage struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewWebhookStorage(db *sql.DB, logger *slog.Logger) *WebhookStorage {
+	return &WebhookStorage{db: db, logger: logger}
+}
+
+func (s *WebhookStorage) CreateEndpoint(ctx context.Context, e *domain.WebhookEndpoint) error {
+	e.ID = uuid.New()
+	e.CreatedAt = time.Now().UTC()
+	s.logger.Info("CreateEndpoint started", "id", e.ID.String(), "url", e.URL)
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("CreateEndpoint begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO webhook_endpoints (id, url, secret, event_types, created_at, tenant_id) VALUES ($1, $2, $3, $4, $5, $6)`,
+		e.ID, e.URL, e.Secret, pq.Array(eventTypeStrings(e.EventTypes)), e.CreatedAt, tenantID(ctx),
+	)
+	if err != nil {
+		s.logger.Error("CreateEndpoint failed", "id", e.ID.String(), "error", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("CreateEndpoint commit failed", "id", e.ID.String(), "error", err)
+		return err
+	}
+
+	s.logger.Info("CreateEndpoint succeeded", "id", e.ID.String())
+	return nil
+}
+
+func (s *WebhookStorage) ListEndpoints(ctx context.Context) ([]*domain.WebhookEndpoint, error) {
+	s.logger.Info("ListEndpoints started")
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("ListEndpoints begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, url, secret, event_types, created_at FROM webhook_endpoints WHERE tenant_id = $1 ORDER BY created_at`,
+		tenantID(ctx),
+	)
+	if err != nil {
+		s.logger.Error("ListEndpoints query failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []*domain.WebhookEndpoint
+	for rows.Next() {
+		e, err := scanEndpoint(rows)
+		if err != nil {
+			s.logger.Error("ListEndpoints scan failed", "error", err)
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("ListEndpoints rows failed", "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("ListEndpoints succeeded", "count", len(endpoints))
+	return endpoints, nil
+}
+
+func (s *WebhookStorage) GetEndpoint(ctx context.Context, id uuid.UUID) (*domain.WebhookEndpoint, error) {
+	s.logger.Info("GetEndpoint started", "id", id.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("GetEndpoint begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx,
+		`SELECT id, url, secret, event_types, created_at FROM webhook_endpoints WHERE id = $1 AND tenant_id = $2`,
+		id, tenantID(ctx),
+	)
+	e, err := scanEndpoint(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.logger.Warn("GetEndpoint not found", "id", id.String())
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		s.logger.Error("GetEndpoint failed", "id", id.String(), "error", err)
+		return nil, err
+	}
+	return e, nil
+}
+
+func (s *WebhookStorage) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	s.logger.Info("DeleteEndpoint started", "id", id.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("DeleteEndpoint begin tx failed", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1 AND tenant_id = $2`, id, tenantID(ctx))
+	if err != nil {
+		s.logger.Error("DeleteEndpoint failed", "id", id.String(), "error", err)
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return domain.ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("DeleteEndpoint commit failed", "id", id.String(), "error", err)
+		return err
+	}
+
+	s.logger.Info("DeleteEndpoint succeeded", "id", id.String())
+	return nil
+}
+
+// EnqueueDeliveries queues the event for every endpoint of the tenant in ctx
+// subscribed to its type and returns how many deliveries were queued.
+func (s *WebhookStorage) EnqueueDeliveries(ctx context.Context, eventID uuid.UUID, eventType domain.EventType, payload []byte) (int, error) {
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("EnqueueDeliveries begin tx failed", "error", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (id, endpoint_id, event_id, event_type, payload, tenant_id)
+		SELECT gen_random_uuid(), e.id, $1, $2, $3, e.tenant_id
+		FROM webhook_endpoints e
+		WHERE e.tenant_id = $4 AND $2 = ANY(e.event_types)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING`,
+		eventID, string(eventType), payload, tenantID(ctx),
+	)
+	if err != nil {
+		s.logger.Error("EnqueueDeliveries failed", "event_id", eventID.String(), "error", err)
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("EnqueueDeliveries commit failed", "event_id", eventID.String(), "error", err)
+		return 0, err
+	}
+	queued, _ := res.RowsAffected()
+	s.logger.Debug("EnqueueDeliveries succeeded", "event_id", eventID.String(), "queued", queued)
+	return int(queued), nil
+}
+
+func (s *WebhookStorage) ListDeliveries(ctx context.Context, endpointID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	s.logger.Info("ListDeliveries started", "endpoint_id", endpointID.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("ListDeliveries begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+deliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.endpoint_id = $1 AND d.tenant_id = $3
+		ORDER BY d.created_at DESC
+		LIMIT $2`,
+		endpointID, limit, tenantID(ctx),
+	)
+	if err != nil {
+		s.logger.Error("ListDeliveries query failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		s.logger.Error("ListDeliveries scan failed", "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("ListDeliveries succeeded", "count", len(deliveries))
+	return deliveries, nil
+}
+
+// ClaimDue returns up to limit deliveries whose next attempt is due, along
+// with their endpoints, and leases them like NotificationStorage.ClaimDue.
+func (s *WebhookStorage) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, map[uuid.UUID]*domain.WebhookEndpoint, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH claimed AS (
+			UPDATE webhook_deliveries SET next_attempt_at = now() + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status IN ('pending', 'failed') AND next_attempt_at <= now()
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+deliveryColumns+`
+		FROM claimed d
+		ORDER BY d.created_at`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		s.logger.Error("ClaimDue deliveries query failed", "error", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		s.logger.Error("ClaimDue deliveries scan failed", "error", err)
+		return nil, nil, err
+	}
+
+	endpoints := make(map[uuid.UUID]*domain.WebhookEndpoint)
+	for _, d := range deliveries {
+		if _, ok := endpoints[d.EndpointID]; ok {
+			continue
+		}
+		// Deliveries are claimed across tenants, so the endpoint is looked
+		// up by ID alone.
+		e, err := scanEndpoint(s.db.QueryRowContext(ctx,
+			`SELECT id, url, secret, event_types, created_at FROM webhook_endpoints WHERE id = $1`,
+			d.EndpointID,
+		))
+		if err != nil {
+			s.logger.Error("ClaimDue endpoint lookup failed", "endpoint_id", d.EndpointID.String(), "error", err)
+			return nil, nil, err
+		}
+		endpoints[e.ID] = e
+	}
+	return deliveries, endpoints, nil
+}
+
+func (s *WebhookStorage) MarkDelivered(ctx context.Context, id uuid.UUID, responseStatus int) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = attempts + 1, response_status = $2, last_error = NULL, delivered_at = now()
+		WHERE id = $1`,
+		id, responseStatus,
+	)
+	if err != nil {
+		s.logger.Error("MarkDelivered failed", "id", id.String(), "error", err)
+	}
+	return err
+}
+
+// MarkFailed records a failed attempt. The delivery is retried at
+// nextAttempt, or moved to the dead status when nextAttempt is nil.
+func (s *WebhookStorage) MarkFailed(ctx context.Context, id uuid.UUID, responseStatus *int, reason string, nextAttempt *time.Time) error {
+	status := domain.DeliveryFailed
+	retryAt := time.Now().UTC()
+	if nextAttempt == nil {
+		status = domain.DeliveryDead
+	} else {
+		retryAt = *nextAttempt
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, response_status = $3, last_error = $4, next_attempt_at = $5
+		WHERE id = $1`,
+		id, status, responseStatus, reason, retryAt,
+	)
+	if err != nil {
+		s.logger.Error("MarkFailed delivery failed", "id", id.String(), "error", err)
+	}
+	return err
+}
+
+// Replay queues a delivery again as a fresh attempt, whatever its status.
+func (s *WebhookStorage) Replay(ctx context.Context, endpointID, deliveryID uuid.UUID) (*domain.WebhookDelivery, error) {
+	s.logger.Info("Replay delivery started", "id", deliveryID.String())
+
+	tx, err := beginTenantTx(ctx, s.db)
+	if err != nil {
+		s.logger.Error("Replay delivery begin tx failed", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		WITH replayed AS (
+			UPDATE webhook_deliveries
+			SET status = 'pending', attempts = 0, next_attempt_at = now(), last_error = NULL
+			WHERE id = $1 AND endpoint_id = $2 AND tenant_id = $3
+			RETURNING *
+		)
+		SELECT `+deliveryColumns+` FROM replayed d`,
+		deliveryID, endpointID, tenantID(ctx),
+	)
+	if err != nil {
+		s.logger.Error("Replay delivery failed", "id", deliveryID.String(), "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		s.logger.Error("Replay delivery scan failed", "id", deliveryID.String(), "error", err)
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("Replay delivery commit failed", "id", deliveryID.String(), "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Replay delivery succeeded", "id", deliveryID.String())
+	return deliveries[0], nil
+}
+
+func scanEndpoint(row rowScanner) (*domain.WebhookEndpoint, error) {
+	var e domain.WebhookEndpoint
+	var eventTypes []string
+	if err := row.Scan(&e.ID, &e.URL, &e.Secret, pq.Array(&eventTypes), &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, t := range eventTypes {
+		e.EventTypes = append(e.EventTypes, domain.EventType(t))
+	}
+	return &e, nil
+}
+
+func scanDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		var (
+			d              domain.WebhookDelivery
+			payload        []byte
+			responseStatus sql.NullInt64
+			deliveredAt    sql.NullTime
+		)
+		if err := rows.Scan(
+			&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+			&responseStatus, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &deliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		if responseStatus.Valid {
+			status := int(responseStatus.Int64)
+			d.ResponseStatus = &status
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+func eventTypeStrings(types []domain.EventType) []string {
+	out := make([]string, len(types))
+	for i, t := range types {
+		out[i] = string(t)
+	}
+	return out
+}
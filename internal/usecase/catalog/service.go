@@ -0,0 +1,122 @@
+package catalog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type Storage interface {
+	Create(ctx context.Context, svc *domain.Service) error
+	GetAll(ctx context.Context) ([]*domain.Service, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Service, error)
+	Resolve(ctx context.Context, name string) (*domain.Service, error)
+	Update(ctx context.Context, svc *domain.Service) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type Service struct {
+	storage Storage
+	logger  *slog.Logger
+}
+
+func NewService(s Storage, logger *slog.Logger) *Service {
+	return &Service{storage: s, logger: logger}
+}
+
+// Create, Update and Delete change prices and aliases for the whole tenant
+// and so require auth.ActionCatalogManage.
+func (s *Service) Create(ctx context.Context, svc *domain.Service) error {
+	s.logger.Debug("catalog: create service", "name", svc.Name)
+	if err := auth.Require(ctx, auth.ActionCatalogManage); err != nil {
+		return err
+	}
+	normalize(svc)
+	if err := s.storage.Create(ctx, svc); err != nil {
+		s.logger.Error("catalog: failed to create service", "error", err)
+		return err
+	}
+	s.logger.Info("catalog: service created", "service_id", svc.ID.String())
+	return nil
+}
+
+func (s *Service) GetAll(ctx context.Context) ([]*domain.Service, error) {
+	s.logger.Debug("catalog: get all services")
+	services, err := s.storage.GetAll(ctx)
+	if err != nil {
+		s.logger.Error("catalog: failed to get all services", "error", err)
+		return nil, err
+	}
+	s.logger.Info("catalog: retrieved services", "count", len(services))
+	return services, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*domain.Service, error) {
+	s.logger.Debug("catalog: get service by ID", "service_id", id.String())
+	svc, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("catalog: failed to get service by ID", "service_id", id.String(), "error", err)
+		return nil, err
+	}
+	return svc, nil
+}
+
+// Resolve maps a free-text service name onto its catalog entry. It returns
+// domain.ErrNotFound when neither a canonical name nor an alias matches.
+func (s *Service) Resolve(ctx context.Context, name string) (*domain.Service, error) {
+	s.logger.Debug("catalog: resolve service name", "name", name)
+	return s.storage.Resolve(ctx, name)
+}
+
+func (s *Service) Update(ctx context.Context, svc *domain.Service) error {
+	s.logger.Debug("catalog: update service", "service_id", svc.ID.String())
+	if err := auth.Require(ctx, auth.ActionCatalogManage); err != nil {
+		return err
+	}
+	normalize(svc)
+	if err := s.storage.Update(ctx, svc); err != nil {
+		s.logger.Error("catalog: failed to update service", "service_id", svc.ID.String(), "error", err)
+		return err
+	}
+	s.logger.Info("catalog: service updated", "service_id", svc.ID.String())
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
+	s.logger.Debug("catalog: delete service", "service_id", id.String())
+	if err := auth.Require(ctx, auth.ActionCatalogManage); err != nil {
+		return err
+	}
+	if err := s.storage.Delete(ctx, id); err != nil {
+		s.logger.Error("catalog: failed to delete service", "service_id", id.String(), "error", err)
+		return err
+	}
+	s.logger.Info("catalog: service deleted", "service_id", id.String())
+	return nil
+}
+
+// normalize trims the canonical name and reduces aliases to their
+// normalized, de-duplicated form. The canonical name always resolves on its
+// own, so it is dropped from the alias list.
+func normalize(svc *domain.Service) {
+	svc.Name = strings.Join(strings.Fields(svc.Name), " ")
+	svc.Category = domain.NormalizeLabel(svc.Category)
+
+	canonical := domain.NormalizeServiceName(svc.Name)
+	seen := map[string]bool{canonical: true}
+	aliases := make([]string, 0, len(svc.Aliases))
+	for _, alias := range svc.Aliases {
+		alias = domain.NormalizeServiceName(alias)
+		if alias == "" || seen[alias] {
+			continue
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
+	svc.Aliases = aliases
+}
@@ -0,0 +1,98 @@
+package feed
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const batchSize = 100
+
+// EventLog is the ordered log of subscription events.
+type EventLog interface {
+	ListSince(ctx context.Context, after int64, userID *uuid.UUID, limit int) ([]domain.Event, error)
+	LastSequence(ctx context.Context) (int64, error)
+}
+
+// Service streams the subscription change feed by polling the event log,
+// which keeps every replica's feed identical without any shared state.
+type Service struct {
+	log          EventLog
+	pollInterval time.Duration
+	logger       *slog.Logger
+}
+
+func NewService(log EventLog, pollInterval time.Duration, logger *slog.Logger) *Service {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	return &Service{log: log, pollInterval: pollInterval, logger: logger}
+}
+
+// Watch streams events logged after the given sequence number, or only new
+// events when after is nil. The channel is closed when ctx is cancelled or
+// the log cannot be read. Callers limited to their own subscriptions only
+// see events about them.
+func (s *Service) Watch(ctx context.Context, after *int64, userID *uuid.UUID) (<-chan domain.Event, error) {
+	scoped, restricted, err := auth.Authorize(ctx, auth.ActionSubscriptionsRead)
+	if err != nil {
+		return nil, err
+	}
+	if restricted {
+		if userID != nil && *userID != scoped {
+			return nil, domain.ErrForbidden
+		}
+		userID = &scoped
+	}
+
+	var cursor int64
+	if after != nil {
+		cursor = *after
+	} else {
+		last, err := s.log.LastSequence(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cursor = last
+	}
+
+	events := make(chan domain.Event)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			batch, err := s.log.ListSince(ctx, cursor, userID, batchSize)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Error("feed: failed to read event log", "after", cursor, "error", err)
+				}
+				return
+			}
+			for _, event := range batch {
+				select {
+				case events <- event:
+					cursor = event.Sequence
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(batch) == batchSize {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,112 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"subscription-service/internal/domain"
+	"subscription-service/internal/health"
+	"subscription-service/internal/retry"
+
+	"github.com/google/uuid"
+)
+
+type Storage interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.Notification, error)
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string, nextAttempt *time.Time) error
+}
+
+// Notifier delivers a notification through one channel.
+type Notifier interface {
+	Notify(ctx context.Context, notification domain.Notification) error
+}
+
+// Config is the retry policy of the dispatcher, shared with the other
+// dispatchers.
+type Config = retry.Policy
+
+// defaultConfig fills in the fields left unset in the Config passed to
+// NewDispatcher.
+var defaultConfig = Config{
+	Interval:    time.Minute,
+	BatchSize:   50,
+	MaxAttempts: 5,
+	Backoff:     time.Minute,
+	MaxBackoff:  time.Hour,
+	Timeout:     30 * time.Second,
+}
+
+// Dispatcher delivers due notifications. Failed deliveries are retried with
+// exponential backoff and become dead after MaxAttempts.
+type Dispatcher struct {
+	storage   Storage
+	notifier  Notifier
+	cfg       Config
+	logger    *slog.Logger
+	heartbeat *health.Heartbeat
+}
+
+func NewDispatcher(storage Storage, notifier Notifier, cfg Config, logger *slog.Logger) *Dispatcher {
+	cfg = cfg.WithDefaults(defaultConfig)
+	return &Dispatcher{storage: storage, notifier: notifier, cfg: cfg, logger: logger}
+}
+
+// SetHeartbeat reports the progress of Run to h, for readiness checks.
+func (d *Dispatcher) SetHeartbeat(h *health.Heartbeat) {
+	d.heartbeat = h
+}
+
+// Run delivers due notifications on every interval until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	retry.Poll(ctx, "notifications", d.cfg, d.heartbeat, d.logger, d.RunOnce)
+}
+
+// RunOnce delivers one batch of due notifications and returns how many were
+// sent successfully.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	due, err := d.storage.ClaimDue(ctx, d.cfg.BatchSize, d.cfg.Lease())
+	if err != nil {
+		return 0, err
+	}
+
+	var sent int
+	for _, n := range due {
+		if d.deliver(ctx, n) {
+			sent++
+		}
+	}
+	if len(due) > 0 {
+		d.logger.Info("notifications: batch delivered", "claimed", len(due), "sent", sent)
+	}
+	return sent, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, n domain.Notification) bool {
+	sendCtx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	err := d.notifier.Notify(sendCtx, n)
+	cancel()
+
+	if err == nil {
+		if err := d.storage.MarkSent(ctx, n.ID); err != nil {
+			d.logger.Error("notifications: failed to mark sent", "notification_id", n.ID.String(), "error", err)
+		}
+		return true
+	}
+
+	attempts := n.Attempts + 1
+	next := d.cfg.NextAttempt(time.Now().UTC(), attempts)
+	if next != nil {
+		d.logger.Warn("notifications: delivery failed, will retry",
+			"notification_id", n.ID.String(), "attempts", attempts, "next_attempt_at", *next, "error", err)
+	} else {
+		d.logger.Error("notifications: delivery failed, giving up",
+			"notification_id", n.ID.String(), "attempts", attempts, "error", err)
+	}
+
+	if err := d.storage.MarkFailed(ctx, n.ID, err.Error(), next); err != nil {
+		d.logger.Error("notifications: failed to record failure", "notification_id", n.ID.String(), "error", err)
+	}
+	return false
+}
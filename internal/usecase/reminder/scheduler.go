@@ -0,0 +1,128 @@
+package reminder
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"subscription-service/internal/domain"
+	"subscription-service/internal/health"
+	"subscription-service/pkg/requestctx"
+)
+
+const (
+	defaultWindowDays = 3
+	defaultInterval   = 24 * time.Hour
+)
+
+type Subscriptions interface {
+	Tenants(ctx context.Context) ([]string, error)
+	EachForPeriod(ctx context.Context, filter domain.SubscriptionFilter, period domain.Period, fn func(*domain.Subscription) error) error
+}
+
+type Notifications interface {
+	Enqueue(ctx context.Context, notifications []domain.Notification) (int, error)
+}
+
+// Scheduler periodically queues reminders for subscriptions that renew, end
+// or leave their trial within the next windowDays days.
+type Scheduler struct {
+	subscriptions Subscriptions
+	notifications Notifications
+	windowDays    int
+	interval      time.Duration
+	logger        *slog.Logger
+	heartbeat     *health.Heartbeat
+}
+
+// NewScheduler falls back to a three-day window checked once a day when
+// windowDays or interval are not positive.
+func NewScheduler(subscriptions Subscriptions, notifications Notifications, windowDays int, interval time.Duration, logger *slog.Logger) *Scheduler {
+	if windowDays <= 0 {
+		windowDays = defaultWindowDays
+	}
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	return &Scheduler{
+		subscriptions: subscriptions,
+		notifications: notifications,
+		windowDays:    windowDays,
+		interval:      interval,
+		logger:        logger,
+	}
+}
+
+// SetHeartbeat reports the progress of Run to h, for readiness checks.
+func (s *Scheduler) SetHeartbeat(h *health.Heartbeat) {
+	s.heartbeat = h
+}
+
+// Run checks for reminders right away and then on every interval until ctx
+// is cancelled. Failed runs are logged and retried on the next tick.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.logger.Info("reminders: scheduler started", "window_days", s.windowDays, "interval", s.interval.String())
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunOnce(ctx, domain.Today().Time); err != nil {
+			s.logger.Error("reminders: run failed", "error", err)
+			s.heartbeat.Fail(err)
+		} else {
+			s.heartbeat.Beat(s.interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("reminders: scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce queues the reminders for events from today through the end of the
+// window for every tenant and returns how many new notifications were queued.
+func (s *Scheduler) RunOnce(ctx context.Context, today time.Time) (int, error) {
+	period := domain.Period{From: today, To: today.AddDate(0, 0, s.windowDays)}
+	s.logger.Debug("reminders: run", "from", period.From, "to", period.To)
+
+	tenants, err := s.subscriptions.Tenants(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	for _, tenant := range tenants {
+		queued, err := s.runTenant(requestctx.WithTenant(ctx, tenant), period)
+		if err != nil {
+			return total, err
+		}
+		total += queued
+	}
+	return total, nil
+}
+
+func (s *Scheduler) runTenant(ctx context.Context, period domain.Period) (int, error) {
+	tenant := requestctx.Tenant(ctx)
+	var reminders []domain.Notification
+	err := s.subscriptions.EachForPeriod(ctx, domain.SubscriptionFilter{}, period, func(sub *domain.Subscription) error {
+		reminders = append(reminders, sub.Reminders(period.From, period.To)...)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(reminders) == 0 {
+		s.logger.Info("reminders: nothing to queue", "tenant", tenant)
+		return 0, nil
+	}
+
+	queued, err := s.notifications.Enqueue(ctx, reminders)
+	if err != nil {
+		return 0, err
+	}
+	s.logger.Info("reminders: notifications queued", "tenant", tenant, "found", len(reminders), "queued", queued)
+	return queued, nil
+}
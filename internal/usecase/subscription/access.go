@@ -0,0 +1,75 @@
+package subscription
+
+import (
+	"context"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// scopeFilter authorizes a listing or report and limits it to the caller's
+// own subscriptions unless their role reaches all users. Asking for another
+// user's subscriptions is refused rather than silently narrowed.
+func scopeFilter(ctx context.Context, action auth.Action, filter *domain.SubscriptionFilter) error {
+	userID, restricted, err := auth.Authorize(ctx, action)
+	if err != nil || !restricted {
+		return err
+	}
+	if filter.UserID != nil && *filter.UserID != userID {
+		return domain.ErrForbidden
+	}
+	filter.UserID = &userID
+	return nil
+}
+
+// checkRead lets callers see subscriptions they own or share, or every
+// subscription if their role allows. Others are reported as missing so
+// their existence is not revealed.
+func checkRead(ctx context.Context, sub *domain.Subscription) error {
+	userID, restricted, err := auth.Authorize(ctx, auth.ActionSubscriptionsRead)
+	if err != nil || !restricted {
+		return err
+	}
+	if !sub.Involves(userID) {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// checkWrite authorizes action on sub. Callers limited to their own
+// subscriptions may only change the ones they own; members of a shared
+// subscription may read it but not modify it.
+func checkWrite(ctx context.Context, action auth.Action, sub *domain.Subscription) error {
+	if err := checkRead(ctx, sub); err != nil {
+		return err
+	}
+	return checkOwner(ctx, action, sub.UserID)
+}
+
+// checkOwner refuses callers acting on behalf of another user without the
+// reach to do so.
+func checkOwner(ctx context.Context, action auth.Action, owner uuid.UUID) error {
+	userID, restricted, err := auth.Authorize(ctx, action)
+	if err != nil || !restricted {
+		return err
+	}
+	if owner != userID {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// getForWrite loads a subscription the caller is allowed to perform action
+// on.
+func (s *Service) getForWrite(ctx context.Context, action auth.Action, id uuid.UUID) (*domain.Subscription, error) {
+	sub, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWrite(ctx, action, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
@@ -0,0 +1,62 @@
+package subscription
+
+import (
+	"context"
+	"time"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Cancel schedules the end of a subscription. Without an effective date the
+// subscription runs until the end of its current billing period, or until
+// the end of its start month when it has not started yet. Cancelling never
+// moves an existing end date later. The cancellation reason is stored and a
+// subscription.cancelled event recorded.
+func (s *Service) Cancel(ctx context.Context, id uuid.UUID, effective *domain.YearMonth, reason domain.CancelReason, comment string) (*domain.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "subscription.Service.Cancel")
+	defer span.End()
+	s.log(ctx).Debug("service: cancel subscription", "subscription_id", id.String(), "reason", string(reason))
+
+	sub, err := s.getForWrite(ctx, auth.ActionSubscriptionsWrite, id)
+	if err != nil {
+		s.log(ctx).Error("service: failed to get subscription for cancellation", "subscription_id", id.String(), "error", err)
+		return nil, err
+	}
+	if sub.Cancellation != nil {
+		return nil, domain.ErrAlreadyCancelled
+	}
+
+	now := time.Now().UTC()
+	var endDate domain.YearMonth
+	switch {
+	case effective != nil:
+		endDate = *effective
+		if sub.EndDate != nil && endDate.Last().After(sub.EndDate.Last()) {
+			return nil, domain.ErrCancelAfterEnd
+		}
+	case now.Before(sub.StartDate.First()):
+		endDate = domain.YearMonth{Time: domain.MonthStart(sub.StartDate.Time)}
+	default:
+		endDate = sub.CurrentPeriodEnd(now)
+		if sub.EndDate != nil && endDate.Last().After(sub.EndDate.Last()) {
+			endDate = *sub.EndDate
+		}
+	}
+	if endDate.Last().Before(sub.StartDate.First()) {
+		return nil, domain.ErrCancelBeforeStart
+	}
+
+	cancellation := domain.Cancellation{Reason: reason, Comment: comment, CancelledAt: now}
+	if err := s.storage.Cancel(ctx, id, endDate, cancellation); err != nil {
+		s.log(ctx).Error("service: failed to cancel subscription", "subscription_id", id.String(), "error", err)
+		return nil, err
+	}
+	sub.EndDate = &endDate
+	sub.Cancellation = &cancellation
+	s.log(ctx).Info("service: subscription cancelled", "subscription_id", id.String(), "end_date", endDate.String())
+
+	return sub, nil
+}
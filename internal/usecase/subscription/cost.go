@@ -0,0 +1,120 @@
+package subscription
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/domain"
+)
+
+// TotalCost sums what the matching subscriptions bill within period after
+// discounts, prorating partial months if requested. With a user filter only
+// that user's share of shared subscriptions is counted; callers limited to
+// their own subscriptions always get their share.
+func (s *Service) TotalCost(ctx context.Context, filter domain.SubscriptionFilter, period domain.Period, proration domain.Proration) (int64, error) {
+	ctx, span := tracer.Start(ctx, "subscription.Service.TotalCost")
+	defer span.End()
+	s.log(ctx).Debug("service: calculate total cost",
+		"user_id", filter.UserID,
+		"service_name", filter.ServiceName,
+		"categories", filter.Categories,
+		"tags", filter.Tags,
+		"from", period.From,
+		"to", period.To,
+		"proration", string(proration),
+	)
+	if err := scopeFilter(ctx, auth.ActionReportsRead, &filter); err != nil {
+		return 0, err
+	}
+	var total float64
+	err := s.eachForPeriod(ctx, filter, period, func(sub *domain.Subscription) error {
+		total += sub.Cost(period, filter.UserID, proration)
+		return nil
+	})
+	if err != nil {
+		s.log(ctx).Error("service: failed to calculate total cost", "error", err)
+		return 0, err
+	}
+
+	s.log(ctx).Info("service: total cost calculated", "total", total)
+	return int64(math.Round(total)), nil
+}
+
+// TotalCostByGroup splits the total cost by category, tag or service. A
+// subscription with several categories or tags contributes to each of its
+// groups, so group totals may add up to more than the overall total.
+// Subscriptions without any category or tag are grouped under an empty key.
+func (s *Service) TotalCostByGroup(ctx context.Context, filter domain.SubscriptionFilter, groupBy domain.CostGroupBy, period domain.Period, proration domain.Proration) ([]domain.CostGroup, error) {
+	ctx, span := tracer.Start(ctx, "subscription.Service.TotalCostByGroup")
+	defer span.End()
+	s.log(ctx).Debug("service: calculate grouped total cost", "group_by", string(groupBy), "from", period.From, "to", period.To)
+	if err := scopeFilter(ctx, auth.ActionReportsRead, &filter); err != nil {
+		return nil, err
+	}
+	totals := make(map[string]float64)
+	err := s.eachForPeriod(ctx, filter, period, func(sub *domain.Subscription) error {
+		cost := sub.Cost(period, filter.UserID, proration)
+		for _, key := range groupKeys(sub, groupBy) {
+			totals[key] += cost
+		}
+		return nil
+	})
+	if err != nil {
+		s.log(ctx).Error("service: failed to calculate grouped total cost", "error", err)
+		return nil, err
+	}
+
+	groups := make([]domain.CostGroup, 0, len(totals))
+	for key, total := range totals {
+		groups = append(groups, domain.CostGroup{Key: key, Total: int64(math.Round(total))})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Total != groups[j].Total {
+			return groups[i].Total > groups[j].Total
+		}
+		return groups[i].Key < groups[j].Key
+	})
+
+	s.log(ctx).Info("service: grouped total cost calculated", "groups", len(groups))
+	return groups, nil
+}
+
+// eachForPeriod calls fn for the subscriptions that may cost something
+// within period, one page at a time, so cost reports never hold the whole
+// table in memory.
+func (s *Service) eachForPeriod(ctx context.Context, filter domain.SubscriptionFilter, period domain.Period, fn func(*domain.Subscription) error) error {
+	filter.Categories = domain.NormalizeLabels(filter.Categories)
+	filter.Tags = domain.NormalizeLabels(filter.Tags)
+	if filter.RecordedAt != nil {
+		// Subscriptions outside the period simply cost nothing.
+		subs, err := s.storage.ListRecorded(ctx, filter, *filter.RecordedAt)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			if err := fn(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return s.storage.EachForPeriod(ctx, filter, period, fn)
+}
+
+func groupKeys(sub *domain.Subscription, groupBy domain.CostGroupBy) []string {
+	var keys []string
+	switch groupBy {
+	case domain.GroupByCategory:
+		keys = sub.Categories
+	case domain.GroupByTag:
+		keys = sub.Tags
+	case domain.GroupByService:
+		keys = []string{sub.ServiceName}
+	}
+	if len(keys) == 0 {
+		return []string{""}
+	}
+	return keys
+}
@@ -0,0 +1,55 @@
+package subscription
+
+import (
+	"context"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func (s *Service) AddDiscount(ctx context.Context, d *domain.Discount) error {
+	ctx, span := tracer.Start(ctx, "subscription.Service.AddDiscount")
+	defer span.End()
+	s.log(ctx).Debug("service: add discount", "subscription_id", d.SubscriptionID.String(), "kind", string(d.Kind), "value", d.Value)
+	if _, err := s.getForWrite(ctx, auth.ActionSubscriptionsWrite, d.SubscriptionID); err != nil {
+		return err
+	}
+	if err := s.storage.AddDiscount(ctx, d); err != nil {
+		s.log(ctx).Error("service: failed to add discount", "subscription_id", d.SubscriptionID.String(), "error", err)
+		return err
+	}
+	s.log(ctx).Info("service: discount added", "discount_id", d.ID.String())
+	return nil
+}
+
+func (s *Service) ListDiscounts(ctx context.Context, subscriptionID uuid.UUID) ([]domain.Discount, error) {
+	ctx, span := tracer.Start(ctx, "subscription.Service.ListDiscounts")
+	defer span.End()
+	s.log(ctx).Debug("service: list discounts", "subscription_id", subscriptionID.String())
+	if _, err := s.GetByID(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	discounts, err := s.storage.ListDiscounts(ctx, subscriptionID)
+	if err != nil {
+		s.log(ctx).Error("service: failed to list discounts", "subscription_id", subscriptionID.String(), "error", err)
+		return nil, err
+	}
+	return discounts, nil
+}
+
+func (s *Service) DeleteDiscount(ctx context.Context, subscriptionID, discountID uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "subscription.Service.DeleteDiscount")
+	defer span.End()
+	s.log(ctx).Debug("service: delete discount", "subscription_id", subscriptionID.String(), "discount_id", discountID.String())
+	if _, err := s.getForWrite(ctx, auth.ActionSubscriptionsWrite, subscriptionID); err != nil {
+		return err
+	}
+	if err := s.storage.DeleteDiscount(ctx, subscriptionID, discountID); err != nil {
+		s.log(ctx).Error("service: failed to delete discount", "discount_id", discountID.String(), "error", err)
+		return err
+	}
+	s.log(ctx).Info("service: discount deleted", "discount_id", discountID.String())
+	return nil
+}
@@ -0,0 +1,64 @@
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// History returns the recorded changes to a subscription, oldest first,
+// including its deletion.
+func (s *Service) History(ctx context.Context, id uuid.UUID) ([]domain.AuditEntry, error) {
+	ctx, span := tracer.Start(ctx, "subscription.Service.History")
+	defer span.End()
+	s.log(ctx).Debug("service: subscription history", "subscription_id", id.String())
+
+	entries, err := s.storage.History(ctx, id)
+	if err != nil {
+		s.log(ctx).Error("service: failed to get subscription history", "subscription_id", id.String(), "error", err)
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	if err := checkReadHistory(ctx, entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetByIDAt returns the subscription as it was recorded at the given
+// moment, so past reports can be reproduced after later edits.
+func (s *Service) GetByIDAt(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "subscription.Service.GetByIDAt")
+	defer span.End()
+	s.log(ctx).Debug("service: get recorded subscription", "subscription_id", id.String(), "at", at)
+	sub, err := s.storage.GetRecorded(ctx, id, at)
+	if err != nil {
+		s.log(ctx).Error("service: failed to get recorded subscription", "subscription_id", id.String(), "error", err)
+		return nil, err
+	}
+	if err := checkRead(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// checkReadHistory applies the read check to the latest recorded state, so
+// the history of a deleted subscription stays visible to its users.
+func checkReadHistory(ctx context.Context, entries []domain.AuditEntry) error {
+	last := entries[len(entries)-1]
+	snapshot := last.After
+	if len(snapshot) == 0 {
+		snapshot = last.Before
+	}
+	var sub domain.Subscription
+	if err := json.Unmarshal(snapshot, &sub); err != nil {
+		return err
+	}
+	return checkRead(ctx, &sub)
+}
@@ -0,0 +1,113 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// pauseStorage serves one subscription and records the pauses added to it.
+// The other Storage methods are not used by Pause.
+type pauseStorage struct {
+	Storage
+	sub   *domain.Subscription
+	added []domain.Pause
+}
+
+func (s *pauseStorage) GetByID(_ context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	if id != s.sub.ID {
+		return nil, domain.ErrNotFound
+	}
+	copied := *s.sub
+	return &copied, nil
+}
+
+func (s *pauseStorage) AddPause(_ context.Context, _ uuid.UUID, p *domain.Pause) error {
+	s.added = append(s.added, *p)
+	return nil
+}
+
+func TestServicePause(t *testing.T) {
+	today := domain.Today()
+	day := func(offset int) *domain.YearMonth {
+		return &domain.YearMonth{Time: today.AddDate(0, 0, offset), HasDay: true}
+	}
+	start := domain.YearMonth{Time: today.AddDate(0, -6, 0), HasDay: true}
+
+	tests := []struct {
+		name    string
+		sub     domain.Subscription
+		start   *domain.YearMonth
+		until   *domain.YearMonth
+		wantErr error
+	}{
+		{name: "open pause from today", sub: domain.Subscription{StartDate: start}},
+		{name: "until today", sub: domain.Subscription{StartDate: start}, until: day(0)},
+		{
+			name:  "end before the default start",
+			sub:   domain.Subscription{StartDate: start},
+			until: day(-1), wantErr: domain.ErrPauseEndsEarly,
+		},
+		{
+			name:  "end before an explicit start",
+			sub:   domain.Subscription{StartDate: start},
+			start: day(10), until: day(9), wantErr: domain.ErrPauseEndsEarly,
+		},
+		{
+			name: "already paused",
+			sub: domain.Subscription{StartDate: start, Pauses: []domain.Pause{
+				{StartDate: *day(-5)},
+			}},
+			wantErr: domain.ErrAlreadyPaused,
+		},
+		{
+			name: "overlapping a planned pause",
+			sub: domain.Subscription{StartDate: start, Pauses: []domain.Pause{
+				{StartDate: *day(3), EndDate: day(6)},
+			}},
+			until: day(4), wantErr: domain.ErrAlreadyPaused,
+		},
+		{
+			name:    "after the end date",
+			sub:     domain.Subscription{StartDate: start, EndDate: day(-1)},
+			wantErr: domain.ErrNotRunning,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.sub.ID = uuid.New()
+			storage := &pauseStorage{sub: &tt.sub}
+			s := NewService(storage, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			got, err := s.Pause(context.Background(), tt.sub.ID, tt.start, tt.until)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Pause() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(storage.added) != 0 {
+					t.Errorf("Pause() stored %d pauses after an error", len(storage.added))
+				}
+				return
+			}
+			if len(storage.added) != 1 {
+				t.Fatalf("Pause() stored %d pauses, want 1", len(storage.added))
+			}
+			want := today
+			if tt.start != nil {
+				want = *tt.start
+			}
+			if p := storage.added[0]; !p.StartDate.Equal(want.Time) {
+				t.Errorf("pause starts %s, want %s", p.StartDate, want)
+			}
+			if got.OpenPause() == nil && tt.until == nil {
+				t.Error("returned subscription has no open pause")
+			}
+		})
+	}
+}
@@ -2,99 +2,239 @@ package subscription
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"strings"
 	"time"
 
+	"subscription-service/internal/auth"
 	"subscription-service/internal/domain"
+	"subscription-service/pkg/logger"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
 )
 
+// tracer records a span for every Service method, between the request span
+// of the router and the SQL spans of the storage.
+var tracer = otel.Tracer("subscription-service/internal/usecase/subscription")
+
 type Storage interface {
 	Create(ctx context.Context, sub *domain.Subscription) error
-	GetAll(ctx context.Context) ([]*domain.Subscription, error)
+	GetAll(ctx context.Context, filter domain.SubscriptionFilter) ([]*domain.Subscription, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
 	Update(ctx context.Context, sub *domain.Subscription) error
+	Cancel(ctx context.Context, id uuid.UUID, endDate domain.YearMonth, c domain.Cancellation) error
+	AddPause(ctx context.Context, subscriptionID uuid.UUID, p *domain.Pause) error
+	EndPause(ctx context.Context, subscriptionID, pauseID uuid.UUID, end domain.YearMonth) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	TotalCost(ctx context.Context, userID *uuid.UUID, serviceName *string, from, to time.Time) (int64, error)
+	History(ctx context.Context, id uuid.UUID) ([]domain.AuditEntry, error)
+	GetRecorded(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Subscription, error)
+	ListRecorded(ctx context.Context, filter domain.SubscriptionFilter, at time.Time) ([]*domain.Subscription, error)
+	EachForPeriod(ctx context.Context, filter domain.SubscriptionFilter, period domain.Period, fn func(*domain.Subscription) error) error
+	AddDiscount(ctx context.Context, d *domain.Discount) error
+	ListDiscounts(ctx context.Context, subscriptionID uuid.UUID) ([]domain.Discount, error)
+	DeleteDiscount(ctx context.Context, subscriptionID, discountID uuid.UUID) error
+	ListCategories(ctx context.Context) ([]string, error)
+	ListTags(ctx context.Context) ([]string, error)
+}
+
+// Catalog resolves free-text service names to canonical catalog entries.
+type Catalog interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Service, error)
+	Resolve(ctx context.Context, name string) (*domain.Service, error)
 }
 
 type Service struct {
 	storage Storage
+	catalog Catalog
 	logger  *slog.Logger
 }
 
-func NewService(s Storage, logger *slog.Logger) *Service {
-	return &Service{storage: s, logger: logger}
+func NewService(s Storage, catalog Catalog, logger *slog.Logger) *Service {
+	return &Service{storage: s, catalog: catalog, logger: logger}
+}
+
+// log returns the request logger carried by ctx, falling back to the
+// service logger for calls made outside a request.
+func (s *Service) log(ctx context.Context) *slog.Logger {
+	return logger.FromContext(ctx, s.logger)
 }
 
 func (s *Service) Create(ctx context.Context, sub *domain.Subscription) error {
-	s.logger.Debug("service: create subscription", "service_name", sub.ServiceName, "user_id", sub.UserID.String())
+	ctx, span := tracer.Start(ctx, "subscription.Service.Create")
+	defer span.End()
+	s.log(ctx).Debug("service: create subscription", "service_name", sub.ServiceName, "user_id", sub.UserID.String())
+	if err := checkOwner(ctx, auth.ActionSubscriptionsWrite, sub.UserID); err != nil {
+		return err
+	}
+	if err := s.normalizeService(ctx, sub); err != nil {
+		return err
+	}
+	sub.IncludeOwner()
 	err := s.storage.Create(ctx, sub)
 	if err != nil {
-		s.logger.Error("service: failed to create subscription", "error", err)
+		s.log(ctx).Error("service: failed to create subscription", "error", err)
 		return err
 	}
-	s.logger.Info("service: subscription created", "subscription_id", sub.ID.String())
+	s.log(ctx).Info("service: subscription created", "subscription_id", sub.ID.String())
 	return nil
 }
 
-func (s *Service) GetAll(ctx context.Context) ([]*domain.Subscription, error) {
-	s.logger.Debug("service: get all subscriptions")
-	subs, err := s.storage.GetAll(ctx)
+func (s *Service) GetAll(ctx context.Context, filter domain.SubscriptionFilter) ([]*domain.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "subscription.Service.GetAll")
+	defer span.End()
+	s.log(ctx).Debug("service: get all subscriptions")
+	if err := scopeFilter(ctx, auth.ActionSubscriptionsRead, &filter); err != nil {
+		return nil, err
+	}
+	filter.Categories = domain.NormalizeLabels(filter.Categories)
+	filter.Tags = domain.NormalizeLabels(filter.Tags)
+	var (
+		subs []*domain.Subscription
+		err  error
+	)
+	if filter.RecordedAt != nil {
+		subs, err = s.storage.ListRecorded(ctx, filter, *filter.RecordedAt)
+	} else {
+		subs, err = s.storage.GetAll(ctx, filter)
+	}
 	if err != nil {
-		s.logger.Error("service: failed to get all subscriptions", "error", err)
+		s.log(ctx).Error("service: failed to get all subscriptions", "error", err)
 		return nil, err
 	}
-	s.logger.Info("service: retrieved subscriptions", "count", len(subs))
+	if filter.Status != "" {
+		subs = filterByStatus(subs, filter.Status, filter.AsOf)
+	}
+	s.log(ctx).Info("service: retrieved subscriptions", "count", len(subs))
 	return subs, nil
 }
 
 func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
-	s.logger.Debug("service: get subscription by ID", "subscription_id", id.String())
+	ctx, span := tracer.Start(ctx, "subscription.Service.GetByID")
+	defer span.End()
+	s.log(ctx).Debug("service: get subscription by ID", "subscription_id", id.String())
 	sub, err := s.storage.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("service: failed to get subscription by ID", "subscription_id", id.String(), "error", err)
+		s.log(ctx).Error("service: failed to get subscription by ID", "subscription_id", id.String(), "error", err)
+		return nil, err
+	}
+	if err := checkRead(ctx, sub); err != nil {
 		return nil, err
 	}
-	s.logger.Info("service: subscription retrieved", "subscription_id", id.String())
+	s.log(ctx).Info("service: subscription retrieved", "subscription_id", id.String())
 	return sub, nil
 }
 
 func (s *Service) Update(ctx context.Context, sub *domain.Subscription) error {
-	s.logger.Debug("service: update subscription", "subscription_id", sub.ID.String())
-	err := s.storage.Update(ctx, sub)
+	ctx, span := tracer.Start(ctx, "subscription.Service.Update")
+	defer span.End()
+	s.log(ctx).Debug("service: update subscription", "subscription_id", sub.ID.String())
+	current, err := s.getForWrite(ctx, auth.ActionSubscriptionsWrite, sub.ID)
 	if err != nil {
-		s.logger.Error("service: failed to update subscription", "subscription_id", sub.ID.String(), "error", err)
 		return err
 	}
-	s.logger.Info("service: subscription updated", "subscription_id", sub.ID.String())
+	if !current.AllowsEndDate(sub.EndDate) {
+		return domain.ErrCancelledEndDate
+	}
+	if err := checkOwner(ctx, auth.ActionSubscriptionsWrite, sub.UserID); err != nil {
+		return err
+	}
+	if err := s.normalizeService(ctx, sub); err != nil {
+		return err
+	}
+	sub.IncludeOwner()
+	if err := s.storage.Update(ctx, sub); err != nil {
+		s.log(ctx).Error("service: failed to update subscription", "subscription_id", sub.ID.String(), "error", err)
+		return err
+	}
+	s.log(ctx).Info("service: subscription updated", "subscription_id", sub.ID.String())
 	return nil
 }
 
 func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
-	s.logger.Debug("service: delete subscription", "subscription_id", id.String())
+	ctx, span := tracer.Start(ctx, "subscription.Service.Delete")
+	defer span.End()
+	s.log(ctx).Debug("service: delete subscription", "subscription_id", id.String())
+	if _, err := s.getForWrite(ctx, auth.ActionSubscriptionsDelete, id); err != nil {
+		return err
+	}
 	err := s.storage.Delete(ctx, id)
 	if err != nil {
-		s.logger.Error("service: failed to delete subscription", "subscription_id", id.String(), "error", err)
+		s.log(ctx).Error("service: failed to delete subscription", "subscription_id", id.String(), "error", err)
 		return err
 	}
-	s.logger.Info("service: subscription deleted", "subscription_id", id.String())
+	s.log(ctx).Info("service: subscription deleted", "subscription_id", id.String())
 	return nil
 }
 
-func (s *Service) TotalCost(ctx context.Context, userID *uuid.UUID, serviceName *string, from, to time.Time) (int64, error) {
-	s.logger.Debug("service: calculate total cost",
-		"user_id", userID,
-		"service_name", serviceName,
-		"from", from,
-		"to", to,
+func (s *Service) ListCategories(ctx context.Context) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "subscription.Service.ListCategories")
+	defer span.End()
+	s.log(ctx).Debug("service: list categories")
+	return s.storage.ListCategories(ctx)
+}
+
+func (s *Service) ListTags(ctx context.Context) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "subscription.Service.ListTags")
+	defer span.End()
+	s.log(ctx).Debug("service: list tags")
+	return s.storage.ListTags(ctx)
+}
+
+// normalizeService links the subscription to its catalog entry, either by
+// the explicit ServiceID or by resolving ServiceName through the aliases, and
+// rewrites ServiceName to the canonical spelling. Names without a catalog
+// entry are kept as free text. A missing price falls back to the catalog
+// default price and missing categories to the catalog category.
+func (s *Service) normalizeService(ctx context.Context, sub *domain.Subscription) error {
+	sub.Categories = domain.NormalizeLabels(sub.Categories)
+	sub.Tags = domain.NormalizeLabels(sub.Tags)
+
+	var (
+		svc *domain.Service
+		err error
 	)
-	total, err := s.storage.TotalCost(ctx, userID, serviceName, from, to)
+	if sub.ServiceID != nil {
+		svc, err = s.catalog.GetByID(ctx, *sub.ServiceID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrUnknownService
+		}
+	} else {
+		svc, err = s.catalog.Resolve(ctx, sub.ServiceName)
+		if errors.Is(err, domain.ErrNotFound) {
+			sub.ServiceName = strings.Join(strings.Fields(sub.ServiceName), " ")
+			svc, err = nil, nil
+		}
+	}
 	if err != nil {
-		s.logger.Error("service: failed to calculate total cost", "error", err)
-		return 0, err
+		s.log(ctx).Error("service: failed to resolve catalog service", "service_name", sub.ServiceName, "error", err)
+		return err
+	}
+
+	if svc != nil {
+		sub.ServiceID = &svc.ID
+		sub.ServiceName = svc.Name
+		if sub.Price == 0 && svc.DefaultPrice != nil {
+			sub.Price = *svc.DefaultPrice
+		}
+		if len(sub.Categories) == 0 && svc.Category != "" {
+			sub.Categories = []string{svc.Category}
+		}
+	}
+	if sub.Price <= 0 {
+		return domain.ErrPriceRequired
+	}
+	return nil
+}
+
+func filterByStatus(subs []*domain.Subscription, status domain.Status, asOf domain.YearMonth) []*domain.Subscription {
+	day := asOf.Last()
+	filtered := subs[:0]
+	for _, sub := range subs {
+		if sub.StatusAt(day) == status {
+			filtered = append(filtered, sub)
+		}
 	}
-	s.logger.Info("service: total cost calculated", "total", total)
-	return total, nil
+	return filtered
 }
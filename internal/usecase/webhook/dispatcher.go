@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"subscription-service/internal/domain"
+	"subscription-service/internal/health"
+	"subscription-service/internal/retry"
+)
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the endpoint secret.
+const (
+	HeaderEventID    = "X-Webhook-Event-Id"
+	HeaderEventType  = "X-Webhook-Event-Type"
+	HeaderDeliveryID = "X-Webhook-Delivery-Id"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+)
+
+// Config is the retry policy of the dispatcher, shared with the other
+// dispatchers.
+type Config = retry.Policy
+
+// defaultConfig fills in the fields left unset in the Config passed to
+// NewDispatcher.
+var defaultConfig = Config{
+	Interval:    5 * time.Second,
+	BatchSize:   50,
+	MaxAttempts: 8,
+	Backoff:     30 * time.Second,
+	MaxBackoff:  6 * time.Hour,
+	Timeout:     10 * time.Second,
+}
+
+// Dispatcher posts queued deliveries to their endpoints, retrying failures
+// with exponential backoff until MaxAttempts is reached.
+type Dispatcher struct {
+	storage   Storage
+	client    *http.Client
+	cfg       Config
+	logger    *slog.Logger
+	heartbeat *health.Heartbeat
+	now       func() time.Time
+}
+
+func NewDispatcher(storage Storage, cfg Config, logger *slog.Logger) *Dispatcher {
+	cfg = cfg.WithDefaults(defaultConfig)
+	return &Dispatcher{
+		storage: storage,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		cfg:     cfg,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// SetHeartbeat reports the progress of Run to h, for readiness checks.
+func (d *Dispatcher) SetHeartbeat(h *health.Heartbeat) {
+	d.heartbeat = h
+}
+
+// Run delivers due webhooks on every interval until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	retry.Poll(ctx, "webhooks", d.cfg, d.heartbeat, d.logger, d.RunOnce)
+}
+
+// RunOnce delivers one batch and returns how many deliveries succeeded.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	due, endpoints, err := d.storage.ClaimDue(ctx, d.cfg.BatchSize, d.cfg.Lease())
+	if err != nil {
+		return 0, err
+	}
+
+	var delivered int
+	for _, delivery := range due {
+		if d.deliver(ctx, endpoints[delivery.EndpointID], delivery) {
+			delivered++
+		}
+	}
+	if len(due) > 0 {
+		d.logger.Info("webhooks: batch delivered", "claimed", len(due), "delivered", delivered)
+	}
+	return delivered, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) bool {
+	status, err := d.post(ctx, endpoint, delivery)
+	if err == nil {
+		if err := d.storage.MarkDelivered(ctx, delivery.ID, status); err != nil {
+			d.logger.Error("webhooks: failed to mark delivered", "delivery_id", delivery.ID.String(), "error", err)
+		}
+		return true
+	}
+
+	var responseStatus *int
+	if status != 0 {
+		responseStatus = &status
+	}
+
+	attempts := delivery.Attempts + 1
+	next := d.cfg.NextAttempt(d.now().UTC(), attempts)
+	if next != nil {
+		d.logger.Warn("webhooks: delivery failed, will retry",
+			"delivery_id", delivery.ID.String(), "attempts", attempts, "next_attempt_at", *next, "error", err)
+	} else {
+		d.logger.Error("webhooks: delivery failed, giving up",
+			"delivery_id", delivery.ID.String(), "attempts", attempts, "error", err)
+	}
+
+	if err := d.storage.MarkFailed(ctx, delivery.ID, responseStatus, err.Error(), next); err != nil {
+		d.logger.Error("webhooks: failed to record failure", "delivery_id", delivery.ID.String(), "error", err)
+	}
+	return false
+}
+
+// post sends the signed payload and returns the response status. Only 2xx
+// responses count as delivered.
+func (d *Dispatcher) post(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, delivery.EventID.String())
+	req.Header.Set(HeaderEventType, string(delivery.EventType))
+	req.Header.Set(HeaderDeliveryID, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign computes the hex HMAC-SHA256 signature receivers verify payloads with.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"subscription-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+		want      string
+	}{
+		{
+			name:   "json body",
+			secret: "secret", timestamp: "1700000000", body: `{"id":1}`,
+			want: "3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11",
+		},
+		{
+			name:   "empty secret and body",
+			secret: "", timestamp: "0", body: "",
+			want: "b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3",
+		},
+		{
+			name:   "plain body",
+			secret: "whsec_abc", timestamp: "1735689600", body: "hello",
+			want: "0d6546d44f744000c85e6b0245593dc1958ed73c5f6895ed0b3e9d2d0a15c164",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// The timestamp is part of the signed content, so a replayed body with
+	// a fresh timestamp does not verify.
+	if Sign("secret", "1700000000", []byte("x")) == Sign("secret", "1700000001", []byte("x")) {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
+
+func TestDispatcherPost(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	delivery := &domain.WebhookDelivery{
+		ID:        uuid.New(),
+		EventID:   uuid.New(),
+		EventType: domain.EventSubscriptionCreated,
+		Payload:   []byte(`{"type":"subscription.created"}`),
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "delivered", status: http.StatusNoContent},
+		{name: "rejected", status: http.StatusInternalServerError, wantErr: true},
+		{name: "redirect is not delivery", status: http.StatusFound, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			d := NewDispatcher(nil, Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			d.now = func() time.Time { return now }
+			endpoint := &domain.WebhookEndpoint{URL: srv.URL, Secret: "whsec_test"}
+
+			status, err := d.post(context.Background(), endpoint, delivery)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("post() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status != tt.status {
+				t.Errorf("post() status = %d, want %d", status, tt.status)
+			}
+
+			if ts := got.Header.Get(HeaderTimestamp); ts != "1735689600" {
+				t.Errorf("%s = %q, want 1735689600", HeaderTimestamp, ts)
+			}
+			want := "sha256=" + Sign("whsec_test", "1735689600", body)
+			if sig := got.Header.Get(HeaderSignature); !hmac.Equal([]byte(sig), []byte(want)) {
+				t.Errorf("%s = %q, want %q", HeaderSignature, sig, want)
+			}
+			if id := got.Header.Get(HeaderEventID); id != delivery.EventID.String() {
+				t.Errorf("%s = %q, want %s", HeaderEventID, id, delivery.EventID)
+			}
+			if string(body) != string(delivery.Payload) {
+				t.Errorf("body = %s, want %s", body, delivery.Payload)
+			}
+		})
+	}
+}
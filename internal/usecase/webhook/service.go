@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/domain"
+	"subscription-service/pkg/requestctx"
+
+	"github.com/google/uuid"
+)
+
+// deliveryHistory is how many recent deliveries ListDeliveries returns.
+const deliveryHistory = 100
+
+type Storage interface {
+	CreateEndpoint(ctx context.Context, e *domain.WebhookEndpoint) error
+	ListEndpoints(ctx context.Context) ([]*domain.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*domain.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+	EnqueueDeliveries(ctx context.Context, eventID uuid.UUID, eventType domain.EventType, payload []byte) (int, error)
+	ListDeliveries(ctx context.Context, endpointID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, map[uuid.UUID]*domain.WebhookEndpoint, error)
+	MarkDelivered(ctx context.Context, id uuid.UUID, responseStatus int) error
+	MarkFailed(ctx context.Context, id uuid.UUID, responseStatus *int, reason string, nextAttempt *time.Time) error
+	Replay(ctx context.Context, endpointID, deliveryID uuid.UUID) (*domain.WebhookDelivery, error)
+}
+
+// Service manages webhook endpoints and queues deliveries for domain events.
+// It implements events.Publisher, so the outbox relay hands it every event.
+type Service struct {
+	storage Storage
+	logger  *slog.Logger
+}
+
+func NewService(s Storage, logger *slog.Logger) *Service {
+	return &Service{storage: s, logger: logger}
+}
+
+// CreateEndpoint registers an endpoint. Without a secret a random one is
+// generated; it is returned only here and used to sign every payload.
+func (s *Service) CreateEndpoint(ctx context.Context, e *domain.WebhookEndpoint) error {
+	s.logger.Debug("webhooks: create endpoint", "url", e.URL)
+	if err := auth.Require(ctx, auth.ActionWebhooksManage); err != nil {
+		return err
+	}
+	if e.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		e.Secret = hex.EncodeToString(secret)
+	}
+	if err := s.storage.CreateEndpoint(ctx, e); err != nil {
+		s.logger.Error("webhooks: failed to create endpoint", "error", err)
+		return err
+	}
+	s.logger.Info("webhooks: endpoint created", "endpoint_id", e.ID.String())
+	return nil
+}
+
+func (s *Service) ListEndpoints(ctx context.Context) ([]*domain.WebhookEndpoint, error) {
+	s.logger.Debug("webhooks: list endpoints")
+	if err := auth.Require(ctx, auth.ActionWebhooksManage); err != nil {
+		return nil, err
+	}
+	return s.storage.ListEndpoints(ctx)
+}
+
+func (s *Service) GetEndpoint(ctx context.Context, id uuid.UUID) (*domain.WebhookEndpoint, error) {
+	s.logger.Debug("webhooks: get endpoint", "endpoint_id", id.String())
+	if err := auth.Require(ctx, auth.ActionWebhooksManage); err != nil {
+		return nil, err
+	}
+	return s.storage.GetEndpoint(ctx, id)
+}
+
+func (s *Service) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	s.logger.Debug("webhooks: delete endpoint", "endpoint_id", id.String())
+	if err := auth.Require(ctx, auth.ActionWebhooksManage); err != nil {
+		return err
+	}
+	if err := s.storage.DeleteEndpoint(ctx, id); err != nil {
+		s.logger.Error("webhooks: failed to delete endpoint", "endpoint_id", id.String(), "error", err)
+		return err
+	}
+	s.logger.Info("webhooks: endpoint deleted", "endpoint_id", id.String())
+	return nil
+}
+
+// ListDeliveries returns the most recent deliveries to an endpoint.
+func (s *Service) ListDeliveries(ctx context.Context, endpointID uuid.UUID) ([]*domain.WebhookDelivery, error) {
+	s.logger.Debug("webhooks: list deliveries", "endpoint_id", endpointID.String())
+	if err := auth.Require(ctx, auth.ActionWebhooksManage); err != nil {
+		return nil, err
+	}
+	if _, err := s.storage.GetEndpoint(ctx, endpointID); err != nil {
+		return nil, err
+	}
+	return s.storage.ListDeliveries(ctx, endpointID, deliveryHistory)
+}
+
+// Replay sends a past delivery again with its original payload.
+func (s *Service) Replay(ctx context.Context, endpointID, deliveryID uuid.UUID) (*domain.WebhookDelivery, error) {
+	s.logger.Debug("webhooks: replay delivery", "endpoint_id", endpointID.String(), "delivery_id", deliveryID.String())
+	if err := auth.Require(ctx, auth.ActionWebhooksManage); err != nil {
+		return nil, err
+	}
+	d, err := s.storage.Replay(ctx, endpointID, deliveryID)
+	if err != nil {
+		s.logger.Error("webhooks: failed to replay delivery", "delivery_id", deliveryID.String(), "error", err)
+		return nil, err
+	}
+	s.logger.Info("webhooks: delivery replayed", "delivery_id", deliveryID.String())
+	return d, nil
+}
+
+// Publish queues the event for every endpoint subscribed to its type. The
+// payload is frozen at this point so retries and replays send the same body.
+func (s *Service) Publish(ctx context.Context, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	// Events are relayed outside of any request, so only endpoints of the
+	// tenant the event belongs to are notified.
+	ctx = requestctx.WithTenant(ctx, event.TenantID)
+	queued, err := s.storage.EnqueueDeliveries(ctx, event.ID, event.Type, payload)
+	if err != nil {
+		return err
+	}
+	if queued > 0 {
+		s.logger.Info("webhooks: deliveries queued", "event_id", event.ID.String(), "event_type", string(event.Type), "count", queued)
+	}
+	return nil
+}
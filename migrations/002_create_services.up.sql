@@ -0,0 +1,15 @@
+CREATE TABLE services (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    category TEXT,
+    default_price INTEGER
+);
+
+CREATE UNIQUE INDEX services_name_idx ON services (lower(name));
+
+CREATE TABLE service_aliases (
+    alias TEXT PRIMARY KEY,
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE
+);
+
+ALTER TABLE subscriptions ADD COLUMN service_id UUID REFERENCES services(id) ON DELETE SET NULL;
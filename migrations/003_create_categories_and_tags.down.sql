@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS subscription_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS subscription_categories;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,42 @@
+CREATE TABLE categories (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+INSERT INTO categories (id, name) VALUES
+    (gen_random_uuid(), 'streaming'),
+    (gen_random_uuid(), 'music'),
+    (gen_random_uuid(), 'cloud'),
+    (gen_random_uuid(), 'productivity'),
+    (gen_random_uuid(), 'gaming'),
+    (gen_random_uuid(), 'news'),
+    (gen_random_uuid(), 'education');
+
+CREATE TABLE subscription_categories (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, category_id)
+);
+
+CREATE TABLE tags (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE subscription_tags (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, tag_id)
+);
+
+-- Seed category links for existing subscriptions from the service catalog.
+INSERT INTO categories (id, name)
+SELECT gen_random_uuid(), lower(category) FROM services
+WHERE category IS NOT NULL
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO subscription_categories (subscription_id, category_id)
+SELECT s.id, c.id
+FROM subscriptions s
+JOIN services sv ON sv.id = s.service_id
+JOIN categories c ON c.name = lower(sv.category);
@@ -0,0 +1,13 @@
+CREATE TABLE subscription_discounts (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    value INTEGER NOT NULL CHECK (value > 0),
+    start_date DATE NOT NULL,
+    end_date DATE,
+    description TEXT,
+    CHECK (kind <> 'percent' OR value <= 100),
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX subscription_discounts_subscription_id_idx ON subscription_discounts (subscription_id);
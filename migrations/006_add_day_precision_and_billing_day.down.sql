@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS billing_day,
+    DROP COLUMN IF EXISTS end_date_has_day,
+    DROP COLUMN IF EXISTS start_date_has_day;
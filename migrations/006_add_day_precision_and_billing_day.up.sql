@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions
+    ADD COLUMN start_date_has_day BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN end_date_has_day BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN billing_day SMALLINT CHECK (billing_day BETWEEN 1 AND 31);
@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions
+    ADD COLUMN cancel_reason TEXT,
+    ADD COLUMN cancel_comment TEXT,
+    ADD COLUMN cancelled_at TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS subscription_pauses;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS trial_end_date_has_day,
+    DROP COLUMN IF EXISTS trial_end_date;
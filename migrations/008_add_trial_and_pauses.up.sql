@@ -0,0 +1,13 @@
+ALTER TABLE subscriptions
+    ADD COLUMN trial_end_date DATE,
+    ADD COLUMN trial_end_date_has_day BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE subscription_pauses (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE,
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX subscription_pauses_subscription_id_idx ON subscription_pauses (subscription_id);
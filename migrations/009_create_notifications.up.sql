@@ -0,0 +1,16 @@
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('renewal', 'ending', 'trial_ending')),
+    due_date DATE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at TIMESTAMPTZ,
+    UNIQUE (subscription_id, kind, due_date)
+);
+
+CREATE INDEX notifications_status_idx ON notifications (status);
+CREATE INDEX notifications_user_id_idx ON notifications (user_id);
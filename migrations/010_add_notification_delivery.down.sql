@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS notifications_due_idx;
+CREATE INDEX notifications_status_idx ON notifications (status);
+
+UPDATE notifications SET status = 'failed' WHERE status = 'dead';
+ALTER TABLE notifications DROP CONSTRAINT notifications_status_check;
+ALTER TABLE notifications
+    ADD CONSTRAINT notifications_status_check CHECK (status IN ('pending', 'sent', 'failed'));
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS next_attempt_at;
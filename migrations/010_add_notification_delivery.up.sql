@@ -0,0 +1,9 @@
+ALTER TABLE notifications
+    ADD COLUMN next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE notifications DROP CONSTRAINT notifications_status_check;
+ALTER TABLE notifications
+    ADD CONSTRAINT notifications_status_check CHECK (status IN ('pending', 'sent', 'failed', 'dead'));
+
+DROP INDEX IF EXISTS notifications_status_idx;
+CREATE INDEX notifications_due_idx ON notifications (next_attempt_at) WHERE status IN ('pending', 'failed');
@@ -0,0 +1,14 @@
+-- No foreign key to subscriptions: the history must outlive deletions.
+CREATE TABLE subscription_audit (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT,
+    request_id TEXT,
+    before JSONB,
+    after JSONB,
+    changes JSONB NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX subscription_audit_subscription_id_idx ON subscription_audit (subscription_id, changed_at);
@@ -0,0 +1 @@
+DELETE FROM subscription_audit WHERE actor = 'migration' AND changed_at = 'epoch'::timestamptz;
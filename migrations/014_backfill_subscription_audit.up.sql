@@ -0,0 +1,51 @@
+-- Seed the audit log with the state of subscriptions that predate it, so
+-- point-in-time reads can see them. The snapshot mirrors the JSON encoding
+-- of domain.Subscription; the epoch timestamp marks it as pre-history.
+INSERT INTO subscription_audit (id, subscription_id, action, actor, before, after, changes, changed_at)
+SELECT gen_random_uuid(), snap.id, 'subscription.created', 'migration', NULL, snap.j,
+    (SELECT jsonb_object_agg(key, jsonb_build_object('from', NULL, 'to', value)) FROM jsonb_each(snap.j)),
+    'epoch'::timestamptz
+FROM (
+    SELECT s.id, jsonb_strip_nulls(jsonb_build_object(
+        'id', s.id,
+        'service_name', s.service_name,
+        'service_id', s.service_id,
+        'price', s.price,
+        'user_id', s.user_id,
+        'start_date', CASE WHEN s.start_date_has_day THEN to_char(s.start_date, 'YYYY-MM-DD') ELSE to_char(s.start_date, 'MM-YYYY') END,
+        'end_date', CASE WHEN s.end_date_has_day THEN to_char(s.end_date, 'YYYY-MM-DD') ELSE to_char(s.end_date, 'MM-YYYY') END,
+        'billing_day', s.billing_day,
+        'trial_end_date', CASE WHEN s.trial_end_date_has_day THEN to_char(s.trial_end_date, 'YYYY-MM-DD') ELSE to_char(s.trial_end_date, 'MM-YYYY') END,
+        'pauses', (
+            SELECT jsonb_agg(jsonb_build_object(
+                'id', p.id, 'start_date', to_char(p.start_date, 'YYYY-MM-DD'), 'end_date', to_char(p.end_date, 'YYYY-MM-DD')
+            ) ORDER BY p.start_date)
+            FROM subscription_pauses p WHERE p.subscription_id = s.id
+        ),
+        'categories', (
+            SELECT jsonb_agg(c.name ORDER BY c.name) FROM subscription_categories sc
+            JOIN categories c ON c.id = sc.category_id WHERE sc.subscription_id = s.id
+        ),
+        'tags', (
+            SELECT jsonb_agg(t.name ORDER BY t.name) FROM subscription_tags st
+            JOIN tags t ON t.id = st.tag_id WHERE st.subscription_id = s.id
+        ),
+        'members', (
+            SELECT jsonb_agg(jsonb_build_object('user_id', m.user_id, 'weight', m.weight) ORDER BY m.user_id)
+            FROM subscription_members m WHERE m.subscription_id = s.id
+        ),
+        'discounts', (
+            SELECT jsonb_agg(jsonb_build_object(
+                'id', d.id, 'subscription_id', d.subscription_id, 'kind', d.kind, 'value', d.value,
+                'start_date', to_char(d.start_date, 'MM-YYYY'), 'end_date', to_char(d.end_date, 'MM-YYYY'),
+                'description', d.description
+            ) ORDER BY d.start_date, d.id)
+            FROM subscription_discounts d WHERE d.subscription_id = s.id
+        ),
+        'cancellation', CASE WHEN s.cancelled_at IS NOT NULL THEN jsonb_build_object(
+            'reason', s.cancel_reason, 'comment', s.cancel_comment, 'cancelled_at', s.cancelled_at
+        ) END
+    )) AS j
+    FROM subscriptions s
+    WHERE NOT EXISTS (SELECT 1 FROM subscription_audit a WHERE a.subscription_id = s.id)
+) snap;
@@ -0,0 +1,48 @@
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'subscriptions', 'services', 'service_aliases', 'categories', 'subscription_categories',
+        'tags', 'subscription_tags', 'subscription_members', 'subscription_discounts',
+        'subscription_pauses', 'notifications', 'webhook_endpoints', 'webhook_deliveries',
+        'outbox', 'subscription_audit', 'api_keys'
+    ] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+    END LOOP;
+END $$;
+
+DROP INDEX IF EXISTS api_keys_tenant_id_idx;
+DROP INDEX IF EXISTS outbox_tenant_id_idx;
+DROP INDEX IF EXISTS webhook_endpoints_tenant_id_idx;
+DROP INDEX IF EXISTS subscriptions_tenant_id_idx;
+
+ALTER TABLE tags DROP CONSTRAINT tags_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_name_key UNIQUE (name);
+
+ALTER TABLE categories DROP CONSTRAINT categories_name_key;
+ALTER TABLE categories ADD CONSTRAINT categories_name_key UNIQUE (name);
+
+ALTER TABLE service_aliases DROP CONSTRAINT service_aliases_pkey;
+ALTER TABLE service_aliases ADD PRIMARY KEY (alias);
+
+DROP INDEX services_name_idx;
+CREATE UNIQUE INDEX services_name_idx ON services (lower(name));
+
+ALTER TABLE api_keys DROP COLUMN tenant_id;
+ALTER TABLE subscription_audit DROP COLUMN tenant_id;
+ALTER TABLE outbox DROP COLUMN tenant_id;
+ALTER TABLE webhook_deliveries DROP COLUMN tenant_id;
+ALTER TABLE webhook_endpoints DROP COLUMN tenant_id;
+ALTER TABLE notifications DROP COLUMN tenant_id;
+ALTER TABLE subscription_pauses DROP COLUMN tenant_id;
+ALTER TABLE subscription_discounts DROP COLUMN tenant_id;
+ALTER TABLE subscription_members DROP COLUMN tenant_id;
+ALTER TABLE subscription_tags DROP COLUMN tenant_id;
+ALTER TABLE tags DROP COLUMN tenant_id;
+ALTER TABLE subscription_categories DROP COLUMN tenant_id;
+ALTER TABLE categories DROP COLUMN tenant_id;
+ALTER TABLE service_aliases DROP COLUMN tenant_id;
+ALTER TABLE services DROP COLUMN tenant_id;
+ALTER TABLE subscriptions DROP COLUMN tenant_id;
@@ -0,0 +1,78 @@
+-- Every row belongs to a tenant (partner brand). Existing data is assigned
+-- to the 'default' tenant.
+ALTER TABLE subscriptions ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE services ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE service_aliases ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE categories ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE subscription_categories ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE tags ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE subscription_tags ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE subscription_members ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE subscription_discounts ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE subscription_pauses ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE notifications ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE webhook_endpoints ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE webhook_deliveries ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE outbox ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE subscription_audit ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- New rows must name their tenant explicitly.
+ALTER TABLE subscriptions ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE services ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE service_aliases ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE categories ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE subscription_categories ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE tags ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE subscription_tags ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE subscription_members ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE subscription_discounts ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE subscription_pauses ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE notifications ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE webhook_endpoints ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE webhook_deliveries ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE outbox ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE subscription_audit ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- Names are unique per tenant rather than globally.
+DROP INDEX services_name_idx;
+CREATE UNIQUE INDEX services_name_idx ON services (tenant_id, lower(name));
+
+ALTER TABLE service_aliases DROP CONSTRAINT service_aliases_pkey;
+ALTER TABLE service_aliases ADD PRIMARY KEY (tenant_id, alias);
+
+ALTER TABLE categories DROP CONSTRAINT categories_name_key;
+ALTER TABLE categories ADD CONSTRAINT categories_name_key UNIQUE (tenant_id, name);
+
+ALTER TABLE tags DROP CONSTRAINT tags_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_name_key UNIQUE (tenant_id, name);
+
+CREATE INDEX subscriptions_tenant_id_idx ON subscriptions (tenant_id, user_id);
+CREATE INDEX webhook_endpoints_tenant_id_idx ON webhook_endpoints (tenant_id);
+CREATE INDEX outbox_tenant_id_idx ON outbox (tenant_id, seq);
+CREATE INDEX api_keys_tenant_id_idx ON api_keys (tenant_id);
+
+-- Row-level security as a second line of defence. Transactions serving a
+-- request set app.tenant_id, which limits them to that tenant's rows;
+-- sessions without it (background workers) are not restricted. Policies
+-- do not apply to the table owner, so they only take effect when the
+-- service connects as a separate, non-owner role.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'subscriptions', 'services', 'service_aliases', 'categories', 'subscription_categories',
+        'tags', 'subscription_tags', 'subscription_members', 'subscription_discounts',
+        'subscription_pauses', 'notifications', 'webhook_endpoints', 'webhook_deliveries',
+        'outbox', 'subscription_audit', 'api_keys'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I USING (
+                COALESCE(current_setting(''app.tenant_id'', true), '''') = ''''
+                OR tenant_id = current_setting(''app.tenant_id'', true)
+            )', t);
+    END LOOP;
+END $$;
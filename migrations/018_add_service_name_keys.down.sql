@@ -0,0 +1,8 @@
+ALTER TABLE subscription_audit DROP COLUMN service_name_key;
+
+DROP INDEX IF EXISTS subscriptions_service_name_key_idx;
+ALTER TABLE subscriptions DROP COLUMN service_name_key;
+
+DROP INDEX services_name_idx;
+CREATE UNIQUE INDEX services_name_idx ON services (tenant_id, lower(name));
+ALTER TABLE services DROP COLUMN name_key;
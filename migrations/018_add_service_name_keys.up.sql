@@ -0,0 +1,19 @@
+-- Service names are compared by a key computed in Go with
+-- domain.NormalizeServiceName (lower case, whitespace folded). Existing
+-- rows are backfilled with the SQL equivalent.
+ALTER TABLE services ADD COLUMN name_key TEXT;
+UPDATE services SET name_key = lower(btrim(regexp_replace(name, '\s+', ' ', 'g')));
+ALTER TABLE services ALTER COLUMN name_key SET NOT NULL;
+
+DROP INDEX services_name_idx;
+CREATE UNIQUE INDEX services_name_idx ON services (tenant_id, name_key);
+
+ALTER TABLE subscriptions ADD COLUMN service_name_key TEXT;
+UPDATE subscriptions SET service_name_key = lower(btrim(regexp_replace(service_name, '\s+', ' ', 'g')));
+ALTER TABLE subscriptions ALTER COLUMN service_name_key SET NOT NULL;
+CREATE INDEX subscriptions_service_name_key_idx ON subscriptions (tenant_id, service_name_key);
+
+ALTER TABLE subscription_audit ADD COLUMN service_name_key TEXT;
+UPDATE subscription_audit
+SET service_name_key = lower(btrim(regexp_replace(after->>'service_name', '\s+', ' ', 'g')))
+WHERE after IS NOT NULL;
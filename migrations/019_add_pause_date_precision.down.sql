@@ -0,0 +1,7 @@
+ALTER TABLE subscription_pauses DROP CONSTRAINT subscription_pauses_check;
+
+ALTER TABLE subscription_pauses
+    DROP COLUMN end_date_has_day,
+    DROP COLUMN start_date_has_day;
+
+ALTER TABLE subscription_pauses ADD CONSTRAINT subscription_pauses_check CHECK (end_date IS NULL OR end_date >= start_date) NOT VALID;
@@ -0,0 +1,19 @@
+-- Pause dates keep the precision they were given with, like subscription
+-- dates: a month-precision end date covers the whole month. Existing pauses
+-- were always read back as full dates and keep that meaning.
+ALTER TABLE subscription_pauses
+    ADD COLUMN start_date_has_day BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN end_date_has_day BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE subscription_pauses
+    ALTER COLUMN start_date_has_day SET DEFAULT FALSE,
+    ALTER COLUMN end_date_has_day SET DEFAULT FALSE;
+
+-- A month-precision end date may lie before a full start date in the same
+-- month and still cover it.
+ALTER TABLE subscription_pauses DROP CONSTRAINT subscription_pauses_check;
+ALTER TABLE subscription_pauses ADD CONSTRAINT subscription_pauses_check CHECK (
+    end_date IS NULL
+    OR end_date >= start_date
+    OR (NOT end_date_has_day AND end_date >= date_trunc('month', start_date))
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS outbox_dead_idx;
+DROP INDEX outbox_pending_idx;
+CREATE INDEX outbox_pending_idx ON outbox (seq) WHERE published_at IS NULL;
+
+ALTER TABLE outbox DROP COLUMN dead_at;
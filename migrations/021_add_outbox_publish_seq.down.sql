@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS outbox_tenant_publish_seq_idx;
+DROP INDEX IF EXISTS outbox_publish_seq_idx;
+ALTER TABLE outbox DROP COLUMN publish_seq;
+DROP SEQUENCE IF EXISTS outbox_publish_seq;
@@ -0,0 +1,15 @@
+-- seq is taken when a change is written, but transactions commit out of
+-- order, so a reader polling seq > cursor can pass over a row that commits
+-- later with a lower seq. publish_seq is assigned by the single outbox
+-- relay as each event leaves the pending state, one committed statement
+-- after another, so it grows in commit order and is safe to resume from.
+CREATE SEQUENCE outbox_publish_seq;
+ALTER TABLE outbox ADD COLUMN publish_seq BIGINT;
+
+-- Keep the cursors clients already hold valid by reusing seq for events
+-- that have left the queue, and continue above it.
+UPDATE outbox SET publish_seq = seq WHERE published_at IS NOT NULL OR dead_at IS NOT NULL;
+SELECT setval('outbox_publish_seq', GREATEST((SELECT COALESCE(MAX(seq), 0) FROM outbox), 1));
+
+CREATE UNIQUE INDEX outbox_publish_seq_idx ON outbox (publish_seq);
+CREATE INDEX outbox_tenant_publish_seq_idx ON outbox (tenant_id, publish_seq) WHERE publish_seq IS NOT NULL;
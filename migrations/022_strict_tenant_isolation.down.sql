@@ -0,0 +1,18 @@
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'subscriptions', 'services', 'service_aliases', 'categories', 'subscription_categories',
+        'tags', 'subscription_tags', 'subscription_members', 'subscription_discounts',
+        'subscription_pauses', 'notifications', 'webhook_endpoints', 'webhook_deliveries',
+        'outbox', 'subscription_audit', 'api_keys'
+    ] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I USING (
+                COALESCE(current_setting(''app.tenant_id'', true), '''') = ''''
+                OR tenant_id = current_setting(''app.tenant_id'', true)
+            )', t);
+    END LOOP;
+END $$;
@@ -0,0 +1,21 @@
+-- Migration 016 let sessions without app.tenant_id see every row, so only
+-- queries that happened to set it were isolated. Every query made for a
+-- request now sets it, and a session without it sees nothing. Background
+-- jobs that work across tenants connect as a role with BYPASSRLS instead.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'subscriptions', 'services', 'service_aliases', 'categories', 'subscription_categories',
+        'tags', 'subscription_tags', 'subscription_members', 'subscription_discounts',
+        'subscription_pauses', 'notifications', 'webhook_endpoints', 'webhook_deliveries',
+        'outbox', 'subscription_audit', 'api_keys'
+    ] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I USING (
+                tenant_id = NULLIF(current_setting(''app.tenant_id'', true), '''')
+            )', t);
+    END LOOP;
+END $$;
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx that carries l, so code further down
+// the call chain logs with the request's attributes.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx, or fallback when there is
+// none, e.g. for work started outside an HTTP request. The logger hands ctx
+// to its handler even for records logged without a context, so they carry
+// the trace and span active in ctx.
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	l, ok := ctx.Value(contextKey{}).(*slog.Logger)
+	if !ok {
+		l = fallback
+	}
+	if l == nil {
+		return nil
+	}
+	h := l.Handler()
+	if bound, ok := h.(*contextHandler); ok {
+		h = bound.Handler
+	}
+	return slog.New(&contextHandler{Handler: h, ctx: ctx})
+}
+
+// contextHandler logs every record with the context it was created for.
+type contextHandler struct {
+	slog.Handler
+	ctx context.Context
+}
+
+func (h *contextHandler) Handle(_ context.Context, record slog.Record) error {
+	return h.Handler.Handle(h.ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), ctx: h.ctx}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), ctx: h.ctx}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"log/slog"
 	"os"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type prefixHandler struct {
@@ -16,6 +18,38 @@ func (h *prefixHandler) Handle(ctx context.Context, record slog.Record) error {
     return h.Handler.Handle(ctx, record)
 }
 
+func (h *prefixHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    return &prefixHandler{Handler: h.Handler.WithAttrs(attrs), prefix: h.prefix}
+}
+
+func (h *prefixHandler) WithGroup(name string) slog.Handler {
+    return &prefixHandler{Handler: h.Handler.WithGroup(name), prefix: h.prefix}
+}
+
+// traceHandler adds the trace and span IDs of the active span to records
+// logged with a context, so log lines can be matched to traces.
+type traceHandler struct {
+    slog.Handler
+}
+
+func (h *traceHandler) Handle(ctx context.Context, record slog.Record) error {
+    if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+        record.AddAttrs(
+            slog.String("trace_id", sc.TraceID().String()),
+            slog.String("span_id", sc.SpanID().String()),
+        )
+    }
+    return h.Handler.Handle(ctx, record)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    return &traceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+    return &traceHandler{Handler: h.Handler.WithGroup(name)}
+}
+
 var Log *slog.Logger
 
 func Init(levelCfg string) {
@@ -44,5 +78,5 @@ func Init(levelCfg string) {
         prefix:  "[Effective Mobile test-project]",
     }
 
-    Log = slog.New(prefixedHandler)
+    Log = slog.New(&traceHandler{Handler: prefixedHandler})
 }
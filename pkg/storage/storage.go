@@ -6,16 +6,31 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 type Config struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Name     string
-	SSLMode  string
+	Host           string
+	Port           string
+	User           string
+	Password       string
+	Name           string
+	SSLMode        string
+	WorkerUser     string
+	WorkerPassword string
+}
+
+// NewWorkerDB connects as the worker role, which bypasses row-level
+// security for jobs that work across tenants. It returns nil when no worker
+// role is configured.
+func NewWorkerDB(cfg Config) *sql.DB {
+	if cfg.WorkerUser == "" {
+		return nil
+	}
+	cfg.User, cfg.Password = cfg.WorkerUser, cfg.WorkerPassword
+	return NewPostgresDB(cfg)
 }
 
 func NewPostgresDB(cfg Config) *sql.DB {
@@ -29,7 +44,15 @@ func NewPostgresDB(cfg Config) *sql.DB {
 		cfg.SSLMode,
 	)
 
-	db, err := sql.Open("postgres", connStr)
+	// Every query gets a span under the span of the calling request.
+	db, err := otelsql.Open("postgres", connStr,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
 	if err != nil {
 		slog.Error("failed to connect to database", "error", err)
 		os.Exit(1)
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"strings"
 	"subscription-service/internal/delivery/dto"
 	"subscription-service/internal/domain"
 	"time"
@@ -15,45 +16,317 @@ func  RequestDtoToDomain(res dto.SubscriptionRequestDTO) (*domain.Subscription,
 		return nil, errors.New("invalid user_id")
 	}
 
-	startTime, err := time.Parse("01-2006", res.StartDate)
+	startDate, err := domain.ParseYearMonth(res.StartDate)
 	if err != nil {
 		return nil, errors.New("invalid start_date format")
 	}
 
 	var endYearMonth *domain.YearMonth
 	if res.EndDate != nil {
-		endTime, err := time.Parse("01-2006", *res.EndDate)
+		ym, err := domain.ParseYearMonth(*res.EndDate)
 		if err != nil {
 			return nil, errors.New("invalid end_date format")
 		}
-		ym := domain.YearMonth{Time: endTime}
 		endYearMonth = &ym
 	}
 
+	var serviceID *uuid.UUID
+	if res.ServiceID != nil {
+		id, err := uuid.Parse(*res.ServiceID)
+		if err != nil {
+			return nil, errors.New("invalid service_id")
+		}
+		serviceID = &id
+	}
+
+	members := make([]domain.Member, 0, len(res.Members))
+	for _, m := range res.Members {
+		memberID, err := uuid.Parse(m.UserID)
+		if err != nil {
+			return nil, errors.New("invalid members.user_id")
+		}
+		weight := m.Weight
+		if weight == 0 {
+			weight = domain.DefaultMemberWeight
+		}
+		members = append(members, domain.Member{UserID: memberID, Weight: weight})
+	}
+
+	var trialEnd *domain.YearMonth
+	if res.TrialEndDate != nil {
+		ym, err := domain.ParseYearMonth(*res.TrialEndDate)
+		if err != nil {
+			return nil, errors.New("invalid trial_end_date format")
+		}
+		trialEnd = &ym
+	}
+
 	sub := &domain.Subscription{
 		ID:          uuid.Nil,
 		ServiceName: res.ServiceName,
+		ServiceID:   serviceID,
 		Price:       res.Price,
 		UserID:      userID,
-		StartDate:   domain.YearMonth{Time: startTime},
+		StartDate:   startDate,
 		EndDate:     endYearMonth,
+		BillingDay:  res.BillingDay,
+		TrialEnd:    trialEnd,
+		Categories:  res.Categories,
+		Tags:        res.Tags,
+		Members:     members,
 	}
 
 	return sub, nil
 }
 
+// DomainToResponseDTO converts a subscription with its status as of today.
 func DomainToResponseDTO(sub *domain.Subscription) dto.SubscriptionResponseDTO {
+	return DomainToResponseDTOAsOf(sub, domain.Today())
+}
+
+// DomainToResponseDTOAsOf converts a subscription with its status derived
+// for the last day covered by asOf.
+func DomainToResponseDTOAsOf(sub *domain.Subscription, asOf domain.YearMonth) dto.SubscriptionResponseDTO {
     var endDate *string
     if sub.EndDate != nil {
-        s := sub.EndDate.Format("01-2006")
+        s := sub.EndDate.String()
         endDate = &s
     }
+    var serviceID *string
+    if sub.ServiceID != nil {
+        s := sub.ServiceID.String()
+        serviceID = &s
+    }
+    var members []dto.MemberDTO
+    for _, m := range sub.Members {
+        members = append(members, dto.MemberDTO{UserID: m.UserID.String(), Weight: m.Weight})
+    }
+    var discounts []dto.DiscountResponseDTO
+    for _, d := range sub.Discounts {
+        discounts = append(discounts, DiscountToResponseDTO(d))
+    }
+    var cancellation *dto.CancellationDTO
+    if sub.Cancellation != nil {
+        cancellation = &dto.CancellationDTO{
+            Reason:      string(sub.Cancellation.Reason),
+            Comment:     sub.Cancellation.Comment,
+            CancelledAt: sub.Cancellation.CancelledAt.Format(time.RFC3339),
+        }
+    }
+    var trialEnd *string
+    if sub.TrialEnd != nil {
+        s := sub.TrialEnd.String()
+        trialEnd = &s
+    }
+    var pauses []dto.PauseDTO
+    for _, p := range sub.Pauses {
+        pauses = append(pauses, PauseToDTO(p))
+    }
     return dto.SubscriptionResponseDTO{
         ID:          sub.ID.String(),
         ServiceName: sub.ServiceName,
+        ServiceID:   serviceID,
         Price:       sub.Price,
         UserID:      sub.UserID.String(),
-        StartDate:   sub.StartDate.Format("01-2006"),
+        StartDate:   sub.StartDate.String(),
         EndDate:     endDate,
+        BillingDay:  sub.BillingDay,
+        TrialEndDate: trialEnd,
+        Status:      string(sub.StatusAt(asOf.Last())),
+        Categories:  sub.Categories,
+        Tags:        sub.Tags,
+        Members:     members,
+        Discounts:   discounts,
+        Cancellation: cancellation,
+        Pauses:      pauses,
     }
 }
+
+func ServiceRequestDtoToDomain(req dto.ServiceRequestDTO) *domain.Service {
+	return &domain.Service{
+		Name:         req.Name,
+		Aliases:      req.Aliases,
+		Category:     req.Category,
+		DefaultPrice: req.DefaultPrice,
+	}
+}
+
+func ServiceToResponseDTO(svc *domain.Service) dto.ServiceResponseDTO {
+	aliases := svc.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return dto.ServiceResponseDTO{
+		ID:           svc.ID.String(),
+		Name:         svc.Name,
+		Aliases:      aliases,
+		Category:     svc.Category,
+		DefaultPrice: svc.DefaultPrice,
+	}
+}
+
+func CostGroupsToDTO(groups []domain.CostGroup) []dto.CostGroupDTO {
+	result := make([]dto.CostGroupDTO, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, dto.CostGroupDTO{Key: g.Key, Total: g.Total})
+	}
+	return result
+}
+
+func DiscountRequestDtoToDomain(subscriptionID uuid.UUID, req dto.DiscountRequestDTO) (*domain.Discount, error) {
+	startTime, err := time.Parse("01-2006", req.StartDate)
+	if err != nil {
+		return nil, errors.New("invalid start_date format")
+	}
+
+	var endYearMonth *domain.YearMonth
+	switch {
+	case req.EndDate != nil:
+		endTime, err := time.Parse("01-2006", *req.EndDate)
+		if err != nil {
+			return nil, errors.New("invalid end_date format")
+		}
+		endYearMonth = &domain.YearMonth{Time: endTime}
+	case req.Months != nil:
+		endYearMonth = &domain.YearMonth{Time: startTime.AddDate(0, *req.Months-1, 0)}
+	}
+
+	return &domain.Discount{
+		SubscriptionID: subscriptionID,
+		Kind:           domain.DiscountKind(req.Kind),
+		Value:          req.Value,
+		StartDate:      domain.YearMonth{Time: startTime},
+		EndDate:        endYearMonth,
+		Description:    strings.TrimSpace(req.Description),
+	}, nil
+}
+
+func DiscountToResponseDTO(d domain.Discount) dto.DiscountResponseDTO {
+	var endDate *string
+	if d.EndDate != nil {
+		s := d.EndDate.Format("01-2006")
+		endDate = &s
+	}
+	return dto.DiscountResponseDTO{
+		ID:          d.ID.String(),
+		Kind:        string(d.Kind),
+		Value:       d.Value,
+		StartDate:   d.StartDate.Format("01-2006"),
+		EndDate:     endDate,
+		Description: d.Description,
+	}
+}
+
+func PauseToDTO(p domain.Pause) dto.PauseDTO {
+	var endDate *string
+	if p.EndDate != nil {
+		s := p.EndDate.String()
+		endDate = &s
+	}
+	return dto.PauseDTO{
+		ID:        p.ID.String(),
+		StartDate: p.StartDate.String(),
+		EndDate:   endDate,
+	}
+}
+
+func WebhookRequestDtoToDomain(req dto.WebhookRequestDTO) *domain.WebhookEndpoint {
+	e := &domain.WebhookEndpoint{URL: req.URL, Secret: req.Secret}
+	for _, t := range req.EventTypes {
+		e.EventTypes = append(e.EventTypes, domain.EventType(t))
+	}
+	return e
+}
+
+// WebhookToResponseDTO leaves out the secret unless withSecret is set.
+func WebhookToResponseDTO(e *domain.WebhookEndpoint, withSecret bool) dto.WebhookResponseDTO {
+	res := dto.WebhookResponseDTO{
+		ID:         e.ID.String(),
+		URL:        e.URL,
+		EventTypes: make([]string, 0, len(e.EventTypes)),
+		CreatedAt:  e.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	for _, t := range e.EventTypes {
+		res.EventTypes = append(res.EventTypes, string(t))
+	}
+	if withSecret {
+		res.Secret = e.Secret
+	}
+	return res
+}
+
+func WebhookDeliveryToDTO(d *domain.WebhookDelivery) dto.WebhookDeliveryDTO {
+	res := dto.WebhookDeliveryDTO{
+		ID:             d.ID.String(),
+		EventID:        d.EventID.String(),
+		EventType:      string(d.EventType),
+		Payload:        d.Payload,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		NextAttemptAt:  d.NextAttemptAt.UTC().Format(time.RFC3339),
+		CreatedAt:      d.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if d.DeliveredAt != nil {
+		s := d.DeliveredAt.UTC().Format(time.RFC3339)
+		res.DeliveredAt = &s
+	}
+	return res
+}
+
+func AuditEntryToDTO(e domain.AuditEntry) dto.AuditEntryDTO {
+	changes := make(map[string]dto.FieldChangeDTO, len(e.Changes))
+	for name, c := range e.Changes {
+		changes[name] = dto.FieldChangeDTO{From: c.From, To: c.To}
+	}
+	return dto.AuditEntryDTO{
+		ID:        e.ID.String(),
+		Action:    string(e.Action),
+		Actor:     e.Actor,
+		RequestID: e.RequestID,
+		Before:    e.Before,
+		After:     e.After,
+		Changes:   changes,
+		ChangedAt: e.ChangedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// APIKeyRequestDtoToDomain expects a validated request.
+func APIKeyRequestDtoToDomain(req dto.APIKeyRequestDTO) *domain.APIKey {
+	k := &domain.APIKey{Name: strings.TrimSpace(req.Name)}
+	for _, s := range req.Scopes {
+		k.Scopes = append(k.Scopes, domain.Scope(s))
+	}
+	if req.ExpiresAt != nil {
+		t, _ := time.Parse(time.RFC3339, *req.ExpiresAt)
+		t = t.UTC()
+		k.ExpiresAt = &t
+	}
+	return k
+}
+
+// APIKeyToResponseDTO includes the key only when one is given, i.e. right
+// after it was issued.
+func APIKeyToResponseDTO(k *domain.APIKey, key string) dto.APIKeyResponseDTO {
+	res := dto.APIKeyResponseDTO{
+		ID:        k.ID.String(),
+		Name:      k.Name,
+		Key:       key,
+		Prefix:    k.Prefix,
+		Scopes:    make([]string, 0, len(k.Scopes)),
+		CreatedAt: k.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	for _, s := range k.Scopes {
+		res.Scopes = append(res.Scopes, string(s))
+	}
+	if k.ExpiresAt != nil {
+		t := k.ExpiresAt.UTC().Format(time.RFC3339)
+		res.ExpiresAt = &t
+	}
+	if k.RevokedAt != nil {
+		t := k.RevokedAt.UTC().Format(time.RFC3339)
+		res.RevokedAt = &t
+	}
+	return res
+}